@@ -13,6 +13,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/nguyenvanduocit/google-mcp/services"
+	"github.com/nguyenvanduocit/google-mcp/util"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/chat/v1"
 	"google.golang.org/api/gmail/v1"
@@ -219,7 +220,10 @@ func requireEnv(name string) string {
 func newCalendarService() *calendar.Service {
 	tokenFile := requireEnv("GOOGLE_TOKEN_FILE")
 	credFile := requireEnv("GOOGLE_CREDENTIALS_FILE")
-	client := services.GoogleHttpClient(tokenFile, credFile)
+	client, err := services.GoogleHttpClient(tokenFile, credFile)
+	if err != nil {
+		fatal("failed to authorize: %v", err)
+	}
 	srv, err := calendar.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		fatal("failed to create Calendar service: %v", err)
@@ -230,7 +234,10 @@ func newCalendarService() *calendar.Service {
 func newGmailService() *gmail.Service {
 	tokenFile := requireEnv("GOOGLE_TOKEN_FILE")
 	credFile := requireEnv("GOOGLE_CREDENTIALS_FILE")
-	client := services.GoogleHttpClient(tokenFile, credFile)
+	client, err := services.GoogleHttpClient(tokenFile, credFile)
+	if err != nil {
+		fatal("failed to authorize: %v", err)
+	}
 	srv, err := gmail.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		fatal("failed to create Gmail service: %v", err)
@@ -241,7 +248,10 @@ func newGmailService() *gmail.Service {
 func newChatService() *chat.Service {
 	tokenFile := requireEnv("GOOGLE_TOKEN_FILE")
 	credFile := requireEnv("GOOGLE_CREDENTIALS_FILE")
-	client := services.GoogleHttpClient(tokenFile, credFile)
+	client, err := services.GoogleHttpClient(tokenFile, credFile)
+	if err != nil {
+		fatal("failed to authorize: %v", err)
+	}
 	srv, err := chat.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		fatal("failed to create Chat service: %v", err)
@@ -252,7 +262,10 @@ func newChatService() *chat.Service {
 func newYouTubeService() *youtube.Service {
 	tokenFile := requireEnv("GOOGLE_TOKEN_FILE")
 	credFile := requireEnv("GOOGLE_CREDENTIALS_FILE")
-	client := services.GoogleHttpClient(tokenFile, credFile)
+	client, err := services.GoogleHttpClient(tokenFile, credFile)
+	if err != nil {
+		fatal("failed to authorize: %v", err)
+	}
 	srv, err := youtube.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		fatal("failed to create YouTube service: %v", err)
@@ -290,11 +303,11 @@ func runCalendarEvent(args []string) {
 		if *summary == "" || *startTime == "" || *endTime == "" {
 			fatal("--summary, --start-time, --end-time required for create")
 		}
-		st, err := time.Parse(time.RFC3339, *startTime)
+		st, err := util.ParseTime(*startTime)
 		if err != nil {
 			fatal("invalid --start-time: %v", err)
 		}
-		et, err := time.Parse(time.RFC3339, *endTime)
+		et, err := util.ParseTime(*endTime)
 		if err != nil {
 			fatal("invalid --end-time: %v", err)
 		}
@@ -330,14 +343,14 @@ func runCalendarEvent(args []string) {
 			ev.Description = *description
 		}
 		if *startTime != "" {
-			st, err := time.Parse(time.RFC3339, *startTime)
+			st, err := util.ParseTime(*startTime)
 			if err != nil {
 				fatal("invalid --start-time: %v", err)
 			}
 			ev.Start.DateTime = st.Format(time.RFC3339)
 		}
 		if *endTime != "" {
-			et, err := time.Parse(time.RFC3339, *endTime)
+			et, err := util.ParseTime(*endTime)
 			if err != nil {
 				fatal("invalid --end-time: %v", err)
 			}
@@ -437,11 +450,11 @@ func runCalendarFindTimeSlot(args []string) {
 
 	svc := newCalendarService()
 
-	sd, err := time.Parse(time.RFC3339, *startDate)
+	sd, err := util.ParseTime(*startDate)
 	if err != nil {
 		fatal("invalid --start-date: %v", err)
 	}
-	ed, err := time.Parse(time.RFC3339, *endDate)
+	ed, err := util.ParseTime(*endDate)
 	if err != nil {
 		fatal("invalid --end-date: %v", err)
 	}
@@ -621,11 +634,11 @@ func runCalendarGetBusyTimes(args []string) {
 
 	svc := newCalendarService()
 
-	sd, err := time.Parse(time.RFC3339, *startDate)
+	sd, err := util.ParseTime(*startDate)
 	if err != nil {
 		fatal("invalid --start-date: %v", err)
 	}
-	ed, err := time.Parse(time.RFC3339, *endDate)
+	ed, err := util.ParseTime(*endDate)
 	if err != nil {
 		fatal("invalid --end-date: %v", err)
 	}
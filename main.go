@@ -1,24 +1,35 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
 	"os"
+	"os/signal"
 	"slices"
 	"strings"
+	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/google-mcp/services"
 	"github.com/nguyenvanduocit/google-mcp/tools"
 )
 
 func main() {
 	envFile := flag.String("env", ".env", "Path to environment file")
+	validate := flag.Bool("validate", false, "Check the credentials and token configuration, print the results, and exit")
 	flag.Parse()
 
 	if err := godotenv.Load(*envFile); err != nil {
 		fmt.Printf("Warning: Error loading env file %s: %v\n", *envFile, err)
 	}
+
+	if *validate {
+		os.Exit(validateConfig())
+	}
 	mcpServer := server.NewMCPServer(
 		"Fetch Kit",
 		"1.0.0",
@@ -50,7 +61,64 @@ func main() {
 		tools.RegisterYouTubeTools(mcpServer)
 	}
 
-	if err := server.ServeStdio(mcpServer); err != nil {
+	if isEnabled("auth") {
+		tools.RegisterAuthTools(mcpServer)
+	}
+
+	if err := serveStdio(mcpServer); err != nil {
 		panic(fmt.Sprintf("Server error: %v", err))
 	}
 }
+
+// serveStdio serves MCP over stdio until stdin closes or SIGINT/SIGTERM
+// arrives. The signal context is also the root context of every Google API
+// request, so on a signal the Google calls of the request in flight are
+// cancelled, its handler returns an error result, and the server returns; a
+// second signal exits immediately. Nothing needs saving before exit: the only
+// state held between requests (cached clients, pending confirmation tokens,
+// the YouTube quota estimate) is in memory and meaningless to a new process.
+func serveStdio(mcpServer *server.MCPServer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	services.SetRootContext(ctx)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Restore default signal handling so a second signal kills the process
+			stop()
+		case <-done:
+		}
+	}()
+
+	stdioServer := server.NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	err := stdioServer.Listen(ctx, os.Stdin, os.Stdout)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("Received shutdown signal, server stopped")
+		return nil
+	}
+	return err
+}
+
+// validateConfig prints a pass/fail checklist of the Google configuration and
+// returns the process exit code.
+func validateConfig() int {
+	exitCode := 0
+	for _, check := range services.ValidateConfig() {
+		status := "PASS"
+		if !check.OK {
+			status = "FAIL"
+			exitCode = 1
+		}
+		if check.Detail != "" {
+			fmt.Printf("[%s] %s: %s\n", status, check.Name, check.Detail)
+		} else {
+			fmt.Printf("[%s] %s\n", status, check.Name)
+		}
+	}
+	return exitCode
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"sync"
 
@@ -24,7 +25,10 @@ func NewGChatService() (*chat.Service, error) {
 		panic("GOOGLE_TOKEN_FILE environment variable must be set")
 	}
 
-	client := GoogleHttpClient(tokenFile, credentialsFile)
+	client, err := GoogleHttpClient(tokenFile, credentialsFile)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize Google Chat API service with default credentials and required scopes
 	srv, err := chat.NewService(ctx, option.WithHTTPClient(client))
@@ -41,4 +45,18 @@ var DefaultGChatService = sync.OnceValue[*chat.Service](func() *chat.Service {
 		panic(fmt.Sprintf("failed to create chat service: %v", err))
 	}
 	return srv
-})
\ No newline at end of file
+})
+
+var gchatServices = NewProfileCache(func(client *http.Client) (*chat.Service, error) {
+	srv, err := chat.NewService(context.Background(), option.WithHTTPClient(client))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat service: %v", err)
+	}
+	return srv, nil
+})
+
+// GChatService returns the Google Chat service for the given credential profile.
+// An empty profile selects the default account.
+func GChatService(profile string) (*chat.Service, error) {
+	return gchatServices.Get(profile)
+}
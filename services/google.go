@@ -7,10 +7,13 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/gmail/v1"
 	"google.golang.org/api/youtube/v3"
 )
@@ -27,6 +30,16 @@ func tokenFromFile(file string) (*oauth2.Token, error) {
 	return tok, err
 }
 
+// checkTokenExpiry returns an error when the token has expired and cannot be
+// refreshed, which would otherwise surface as an opaque failure on the first API call.
+func checkTokenExpiry(tok *oauth2.Token) error {
+	if tok.Expiry.IsZero() || tok.Expiry.After(time.Now()) || tok.RefreshToken != "" {
+		return nil
+	}
+	log.Printf("Google token expired at %s (%s ago) and has no refresh token", tok.Expiry.Format(time.RFC3339), time.Since(tok.Expiry).Round(time.Second))
+	return fmt.Errorf("token expired at %s and has no refresh token; re-run get-google-token to generate a new one", tok.Expiry.Format(time.RFC3339))
+}
+
 func ListChatScopes() []string {
 	return []string{
 		"https://www.googleapis.com/auth/chat.admin.memberships",
@@ -55,8 +68,11 @@ func ListGoogleScopes() []string {
 		gmail.GmailModifyScope,
 		gmail.MailGoogleComScope,
 		gmail.GmailSettingsBasicScope,
+		gmail.GmailSettingsSharingScope,
 		calendar.CalendarScope,
 		calendar.CalendarEventsScope,
+		drive.DriveFileScope,
+		drive.DriveMetadataReadonlyScope,
 		youtube.YoutubeScope,
 		youtube.YoutubeForceSslScope,
 		youtube.YoutubeUploadScope,
@@ -65,27 +81,45 @@ func ListGoogleScopes() []string {
 		youtube.YoutubeReadonlyScope,
 	}
 	scopes = append(scopes, ListChatScopes()...)
+	if DirectoryLookupEnabled() {
+		scopes = append(scopes, admin.AdminDirectoryUserReadonlyScope)
+	}
 	return scopes
 }
 
-func GoogleHttpClient(tokenFile string, credentialsFile string) *http.Client {
-	
+// DirectoryLookupEnabled reports whether ENABLE_DIRECTORY_LOOKUP is set to
+// true. Directory lookups need the Admin SDK user scope, which only Workspace
+// accounts can use, so the scope and the tool using it are opt-in.
+func DirectoryLookupEnabled() bool {
+	return os.Getenv("ENABLE_DIRECTORY_LOOKUP") == "true"
+}
+
+// GoogleHttpClient returns an HTTP client authorized with the token in
+// tokenFile, refreshed using the OAuth client in credentialsFile. Unreadable or
+// invalid files are reported as errors so a bad profile fails only the call
+// that uses it.
+func GoogleHttpClient(tokenFile string, credentialsFile string) (*http.Client, error) {
 	tok, err := tokenFromFile(tokenFile)
 	if err != nil {
-		panic(fmt.Sprintf("failed to read token file: %v", err))
+		return nil, fmt.Errorf("failed to read token file: %v", err)
+	}
+	if err := checkTokenExpiry(tok); err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background()
 	b, err := os.ReadFile(credentialsFile)
 	if err != nil {
-		log.Fatalf("Unable to read client secret file: %v", err)
+		return nil, fmt.Errorf("unable to read client secret file: %v", err)
 	}
 
 	// If modifying these scopes, delete your previously saved token.json.
 	config, err := google.ConfigFromJSON(b, ListGoogleScopes()...)
 	if err != nil {
-		log.Fatalf("Unable to parse client secret file to config: %v", err)
+		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
 	}
 
-	return config.Client(ctx, tok)
+	client := config.Client(ctx, tok)
+	client.Transport = rootContextTransport{base: client.Transport}
+	return client, nil
 }
\ No newline at end of file
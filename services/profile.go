@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	profileCredentialsSuffix = ".credentials.json"
+	profileTokenSuffix       = ".token.json"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ProfileFiles resolves the credentials and token files for a profile.
+// An empty profile selects the default account configured through
+// GOOGLE_CREDENTIALS_FILE and GOOGLE_TOKEN_FILE. Named profiles are looked up in
+// GOOGLE_PROFILES_DIR as {name}.credentials.json and {name}.token.json.
+func ProfileFiles(profile string) (credentialsFile string, tokenFile string, err error) {
+	if profile == "" {
+		credentialsFile = os.Getenv("GOOGLE_CREDENTIALS_FILE")
+		if credentialsFile == "" {
+			return "", "", fmt.Errorf("GOOGLE_CREDENTIALS_FILE environment variable must be set")
+		}
+		tokenFile = os.Getenv("GOOGLE_TOKEN_FILE")
+		if tokenFile == "" {
+			return "", "", fmt.Errorf("GOOGLE_TOKEN_FILE environment variable must be set")
+		}
+		return credentialsFile, tokenFile, nil
+	}
+
+	if !profileNamePattern.MatchString(profile) {
+		return "", "", fmt.Errorf("invalid profile name %q", profile)
+	}
+
+	dir := os.Getenv("GOOGLE_PROFILES_DIR")
+	if dir == "" {
+		return "", "", fmt.Errorf("GOOGLE_PROFILES_DIR environment variable must be set to use profile %q", profile)
+	}
+
+	credentialsFile = filepath.Join(dir, profile+profileCredentialsSuffix)
+	tokenFile = filepath.Join(dir, profile+profileTokenSuffix)
+	for _, file := range []string{credentialsFile, tokenFile} {
+		if _, err := os.Stat(file); err != nil {
+			return "", "", fmt.Errorf("profile %q is not configured: %v", profile, err)
+		}
+	}
+
+	return credentialsFile, tokenFile, nil
+}
+
+// ListProfiles returns the names of the profiles found in GOOGLE_PROFILES_DIR
+// that have both a credentials and a token file.
+func ListProfiles() ([]string, error) {
+	dir := os.Getenv("GOOGLE_PROFILES_DIR")
+	if dir == "" {
+		return []string{}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles directory: %v", err)
+	}
+
+	files := make(map[string]bool)
+	for _, entry := range entries {
+		files[entry.Name()] = true
+	}
+
+	profiles := make([]string, 0)
+	for name := range files {
+		profile, ok := strings.CutSuffix(name, profileCredentialsSuffix)
+		if ok && files[profile+profileTokenSuffix] {
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Strings(profiles)
+
+	return profiles, nil
+}
+
+// ProfileCache lazily builds and caches one value per profile, such as an API
+// service bound to that profile's HTTP client.
+type ProfileCache[T any] struct {
+	mu    sync.Mutex
+	items map[string]T
+	build func(client *http.Client) (T, error)
+}
+
+// NewProfileCache creates a cache that calls build with the profile's HTTP client
+// the first time a profile is requested.
+func NewProfileCache[T any](build func(client *http.Client) (T, error)) *ProfileCache[T] {
+	return &ProfileCache[T]{
+		items: make(map[string]T),
+		build: build,
+	}
+}
+
+// Get returns the cached value for profile, building it on first use.
+func (c *ProfileCache[T]) Get(profile string) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item, ok := c.items[profile]; ok {
+		return item, nil
+	}
+
+	var zero T
+	client, err := ProfileHttpClient(profile)
+	if err != nil {
+		return zero, err
+	}
+
+	item, err := c.build(client)
+	if err != nil {
+		return zero, err
+	}
+	c.items[profile] = item
+
+	return item, nil
+}
+
+var (
+	profileClientsMu sync.Mutex
+	profileClients   = make(map[string]*http.Client)
+)
+
+// ProfileHttpClient returns an authorized HTTP client for the given profile.
+// Clients are cached so each profile's token is loaded only once.
+func ProfileHttpClient(profile string) (*http.Client, error) {
+	profileClientsMu.Lock()
+	defer profileClientsMu.Unlock()
+
+	if client, ok := profileClients[profile]; ok {
+		return client, nil
+	}
+
+	credentialsFile, tokenFile, err := ProfileFiles(profile)
+	if err != nil {
+		return nil, err
+	}
+	client, err := GoogleHttpClient(tokenFile, credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	profileClients[profile] = client
+
+	return client, nil
+}
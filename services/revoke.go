@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// RevokeToken revokes the OAuth grant stored in tokenFile and removes the file.
+// The refresh token is preferred since revoking it invalidates the whole grant;
+// the access token is used when no refresh token is available.
+func RevokeToken(tokenFile string) (string, error) {
+	tok, err := tokenFromFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %v", err)
+	}
+
+	tokenType := "refresh_token"
+	token := tok.RefreshToken
+	if token == "" {
+		tokenType = "access_token"
+		token = tok.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("token file %s contains no access or refresh token", tokenFile)
+	}
+
+	form := url.Values{"token": {token}}
+	resp, err := DefaultHttpClient().Post(googleRevokeURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to call revocation endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("revocation failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := os.Remove(tokenFile); err != nil {
+		return tokenType, fmt.Errorf("token revoked but failed to delete token file: %v", err)
+	}
+
+	return tokenType, nil
+}
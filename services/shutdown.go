@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+var (
+	rootContextMu sync.Mutex
+	rootContext   = context.Background()
+)
+
+// SetRootContext sets the context that bounds every request made through the
+// authorized Google clients. When it is cancelled, requests in flight fail
+// with context.Canceled, so a shutting-down server does not wait on them.
+func SetRootContext(ctx context.Context) {
+	rootContextMu.Lock()
+	defer rootContextMu.Unlock()
+	rootContext = ctx
+}
+
+func currentRootContext() context.Context {
+	rootContextMu.Lock()
+	defer rootContextMu.Unlock()
+	return rootContext
+}
+
+// rootContextTransport cancels each request when the root context is
+// cancelled, in addition to the request's own context.
+type rootContextTransport struct {
+	base http.RoundTripper
+}
+
+func (t rootContextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	root := currentRootContext()
+	if root.Done() == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(root, cancel)
+	release := func() {
+		stop()
+		cancel()
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// The body is read after RoundTrip returns, so keep the context alive
+	// until it is closed
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseOnClose calls release once when the body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
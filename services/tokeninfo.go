@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+const googleTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+
+// TokenInfo describes the OAuth grant stored in a token file.
+type TokenInfo struct {
+	Scopes          []string
+	Email           string
+	Expiry          time.Time
+	HasRefreshToken bool
+	// Refreshed reports whether the stored access token had expired and a new
+	// one was obtained (in memory only) to query the token info endpoint.
+	Refreshed bool
+}
+
+// GetTokenInfo reads tokenFile and asks Google's tokeninfo endpoint which
+// scopes the grant holds. An expired access token is refreshed first when a
+// refresh token is available.
+func GetTokenInfo(tokenFile string, credentialsFile string) (*TokenInfo, error) {
+	tok, err := tokenFromFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %v", err)
+	}
+
+	info := &TokenInfo{
+		Expiry:          tok.Expiry,
+		HasRefreshToken: tok.RefreshToken != "",
+	}
+
+	accessToken := tok.AccessToken
+	if !tok.Valid() {
+		if !info.HasRefreshToken {
+			return info, fmt.Errorf("token expired at %s and has no refresh token", tok.Expiry.Format(time.RFC3339))
+		}
+
+		b, err := os.ReadFile(credentialsFile)
+		if err != nil {
+			return info, fmt.Errorf("failed to read credentials file: %v", err)
+		}
+		config, err := google.ConfigFromJSON(b, ListGoogleScopes()...)
+		if err != nil {
+			return info, fmt.Errorf("failed to parse credentials file: %v", err)
+		}
+
+		fresh, err := config.TokenSource(context.Background(), tok).Token()
+		if err != nil {
+			return info, fmt.Errorf("failed to refresh token: %v", err)
+		}
+		accessToken = fresh.AccessToken
+		info.Expiry = fresh.Expiry
+		info.Refreshed = true
+	}
+
+	resp, err := DefaultHttpClient().Get(googleTokenInfoURL + "?" + url.Values{"access_token": {accessToken}}.Encode())
+	if err != nil {
+		return info, fmt.Errorf("failed to call token info endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return info, fmt.Errorf("failed to read token info response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("token info failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Scope string `json:"scope"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return info, fmt.Errorf("failed to parse token info response: %v", err)
+	}
+
+	info.Scopes = strings.Fields(payload.Scope)
+	info.Email = payload.Email
+
+	return info, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// ConfigCheck is the outcome of one configuration check.
+type ConfigCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// ValidateConfig checks the default account's credentials and token files:
+// that both exist and parse, that the credentials are an installed-app OAuth
+// client, and that the token has a refresh token and a
+// usable or refreshable access token. Checks that depend on an earlier failed
+// check are skipped.
+func ValidateConfig() []ConfigCheck {
+	checks := make([]ConfigCheck, 0)
+	add := func(name string, err error, detail string) bool {
+		check := ConfigCheck{Name: name, OK: err == nil, Detail: detail}
+		if err != nil {
+			check.Detail = err.Error()
+		}
+		checks = append(checks, check)
+		return err == nil
+	}
+
+	credentialsFile, tokenFile, err := ProfileFiles("")
+	if !add("GOOGLE_CREDENTIALS_FILE and GOOGLE_TOKEN_FILE are set", err, "") {
+		return checks
+	}
+
+	credentials, err := os.ReadFile(credentialsFile)
+	if !add("Credentials file is readable", err, credentialsFile) {
+		return checks
+	}
+
+	credentialsType, err := credentialsFileType(credentials)
+	if !add("Credentials are an installed-app OAuth client", err, credentialsType) {
+		return checks
+	}
+
+	tok, err := tokenFromFile(tokenFile)
+	if !add("Token file is readable and valid JSON", err, tokenFile) {
+		return checks
+	}
+
+	if tok.RefreshToken == "" {
+		add("Token has a refresh token", fmt.Errorf("no refresh token; access stops when the token expires. Re-run get-google-token"), "")
+	} else {
+		add("Token has a refresh token", nil, "")
+	}
+
+	if tok.Valid() {
+		add("Access token is unexpired", nil, fmt.Sprintf("expires %s", tok.Expiry.Format(time.RFC3339)))
+		return checks
+	}
+	if tok.RefreshToken == "" {
+		add("Access token is unexpired or refreshable", fmt.Errorf("access token expired at %s and cannot be refreshed", tok.Expiry.Format(time.RFC3339)), "")
+		return checks
+	}
+
+	config, err := google.ConfigFromJSON(credentials, ListGoogleScopes()...)
+	if err == nil {
+		var fresh *oauth2.Token
+		fresh, err = config.TokenSource(context.Background(), tok).Token()
+		if err == nil {
+			add("Access token is unexpired or refreshable", nil, fmt.Sprintf("refreshed; new token expires %s", fresh.Expiry.Format(time.RFC3339)))
+			return checks
+		}
+	}
+	add("Access token is unexpired or refreshable", fmt.Errorf("failed to refresh token: %v", err), "")
+	return checks
+}
+
+// credentialsFileType returns "installed" for an installed-app OAuth client,
+// the only kind GoogleHttpClient supports, and an error otherwise.
+func credentialsFileType(credentials []byte) (string, error) {
+	var payload struct {
+		Type      string          `json:"type"`
+		Installed json.RawMessage `json:"installed"`
+		Web       json.RawMessage `json:"web"`
+	}
+	if err := json.Unmarshal(credentials, &payload); err != nil {
+		return "", fmt.Errorf("credentials file is not valid JSON: %v", err)
+	}
+
+	switch {
+	case payload.Installed != nil:
+		return "installed", nil
+	case payload.Type == "service_account":
+		return "service_account", fmt.Errorf("credentials are a service account key, which is not supported; create a Desktop app OAuth client instead")
+	case payload.Web != nil:
+		return "web", fmt.Errorf("credentials are a web application client; create a Desktop app OAuth client instead")
+	default:
+		return "", fmt.Errorf("unrecognized credentials file; expected an installed-app (Desktop app) OAuth client")
+	}
+}
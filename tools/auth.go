@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/google-mcp/services"
+	"github.com/nguyenvanduocit/google-mcp/util"
+	"gopkg.in/yaml.v3"
+)
+
+func RegisterAuthTools(s *server.MCPServer) {
+	revokeTool := mcp.NewTool("google_revoke",
+		mcp.WithDescription("Sign out by revoking the current Google OAuth token and deleting the local token file. All Google tools stop working until a new token is generated"),
+		withConfirmation(),
+		withProfile(),
+	)
+	s.AddTool(revokeTool, util.ErrorGuardNamed(revokeTool.Name, googleRevokeHandler))
+
+	tokenInfoTool := mcp.NewTool("google_token_info",
+		mcp.WithDescription("Show the scopes, expiry and refresh token status of the Google OAuth token the server is using"),
+		withProfile(),
+	)
+	s.AddTool(tokenInfoTool, util.ErrorGuardNamed(tokenInfoTool.Name, googleTokenInfoHandler))
+
+	listProfilesTool := mcp.NewTool("google_list_profiles",
+		mcp.WithDescription("List the credential profiles available in GOOGLE_PROFILES_DIR. Pass a profile name as the 'profile' argument of any tool to act on that account"),
+	)
+	s.AddTool(listProfilesTool, util.ErrorGuardNamed(listProfilesTool.Name, googleListProfilesHandler))
+}
+
+func googleListProfilesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profiles, err := services.ListProfiles()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"count":    len(profiles),
+		"profiles": profiles,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal profiles: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func googleTokenInfoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+
+	credentialsFile, tokenFile, err := services.ProfileFiles(profile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	info, err := services.GetTokenInfo(tokenFile, credentialsFile)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get token info: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"tokenFile":       tokenFile,
+		"scopes":          info.Scopes,
+		"hasRefreshToken": info.HasRefreshToken,
+	}
+	if info.Email != "" {
+		result["email"] = info.Email
+	}
+	if !info.Expiry.IsZero() {
+		result["expiry"] = info.Expiry.Format(time.RFC3339)
+		result["expiresIn"] = time.Until(info.Expiry).Round(time.Second).String()
+	}
+	if info.Refreshed {
+		result["note"] = "The stored access token had expired; a refreshed token was used to look up the scopes."
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal token info: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func googleRevokeHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+
+	_, tokenFile, err := services.ProfileFiles(profile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	impact := fmt.Sprintf("Revokes the Google OAuth token and deletes %s. All Google tools for this profile stop working until a new token is generated.", tokenFile)
+	if confirm := requireConfirmation(arguments, "google_revoke:"+profile, impact); confirm != nil {
+		return confirm, nil
+	}
+
+	revokedType, err := services.RevokeToken(tokenFile)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to revoke token: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"revoked":   true,
+		"tokenType": revokedType,
+		"tokenFile": tokenFile,
+		"hint":      "Re-run scripts/get-google-token to authorize again, then restart the server.",
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
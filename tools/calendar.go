@@ -2,10 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -13,6 +18,8 @@ import (
 	"github.com/nguyenvanduocit/google-mcp/services"
 	"github.com/nguyenvanduocit/google-mcp/util"
 	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"gopkg.in/yaml.v3"
 )
@@ -20,35 +27,65 @@ import (
 func RegisterCalendarTools(s *server.MCPServer) {
 	// Unified event management tool
 	eventTool := mcp.NewTool("calendar_event",
-		mcp.WithDescription("Manage Google Calendar events - create, update, list, or respond to events"),
-		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: create, update, list, respond")),
-		mcp.WithString("event_id", mcp.Description("ID of the event (required for update/respond actions)")),
+		mcp.WithDescription("Manage Google Calendar events - create, get, update, duplicate, move, list, respond to events, or check a proposed time for conflicts"),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: create, get, update, duplicate, move, list, respond, check_conflicts")),
+		mcp.WithString("event_id", mcp.Description("ID of the event (required for get/update/duplicate/move/respond actions)")),
+		mcp.WithString("calendar_id", mcp.Description("ID of the calendar the event belongs to or is created in (default: primary)")),
+		mcp.WithString("organizer_calendar_id", mcp.Description("Shared calendar to create the event on as its organizer, so invites come from that calendar rather than you (create action; requires writer access)")),
+		mcp.WithString("destination_calendar_id", mcp.Description("ID of the calendar to move the event to (required for move action)")),
 		mcp.WithString("summary", mcp.Description("Title of the event (required for create, optional for update)")),
 		mcp.WithString("description", mcp.Description("Description of the event")),
-		mcp.WithString("start_time", mcp.Description("Start time in RFC3339 format (required for create, optional for update/list)")),
-		mcp.WithString("end_time", mcp.Description("End time in RFC3339 format (required for create, optional for update/list)")),
-		mcp.WithString("attendees", mcp.Description("Comma-separated list of attendee email addresses")),
+		mcp.WithString("start_time", mcp.Description("Start time in RFC3339 format (required for create/check_conflicts, optional for update/list; for duplicate, the copy's new start time)")),
+		mcp.WithString("end_time", mcp.Description("End time in RFC3339 format (required for create/check_conflicts, optional for update/list)")),
+		mcp.WithString("attendees", mcp.Description("Comma-separated list of attendee email addresses (for check_conflicts, whose free/busy to check as well)")),
+		mcp.WithString("optional_attendees", mcp.Description("Comma-separated attendee email addresses to mark as optional, added if not already attendees (create/update actions)")),
+		mcp.WithString("organizer_response", mcp.Description("Your own response as organizer: accepted, tentative, or needsAction (create action, default: Calendar's default of accepted)")),
+		mcp.WithString("color_id", mcp.Description("Event color ID from calendar_list_colors (create/update actions)")),
+		mcp.WithBoolean("all_day", mcp.Description("Create an all-day event (create action). start_time and end_time are then dates (YYYY-MM-DD) and end_time is the last day, inclusive; defaults to start_time for a single day")),
 		mcp.WithString("time_min", mcp.Description("Start time for search in RFC3339 format (list action, default: now)")),
 		mcp.WithString("time_max", mcp.Description("End time for search in RFC3339 format (list action, default: 1 week from now)")),
-		mcp.WithNumber("max_results", mcp.Description("Maximum number of events to return (list action, default: 10)")),
+		mcp.WithNumber("max_results", mcp.Description("Maximum number of events to return (list action, default: 10; per page when auto_paginate is set)")),
 		mcp.WithString("response", mcp.Description("Your response: accepted, declined, or tentative (respond action)")),
+		mcp.WithString("output_format", mcp.Description("Output format for the list action: yaml (default) or csv")),
+		mcp.WithBoolean("show_deleted", mcp.Description("Include cancelled events, marked by a status field (list action, default: false)")),
+		mcp.WithString("attachment_file_ids", mcp.Description("Comma-separated Drive file IDs to attach (create/update actions; update adds to existing attachments)")),
+		mcp.WithBoolean("preview", mcp.Description("Return a field-by-field before/after diff of the changes without saving them (update action, default: false)")),
+		mcp.WithBoolean("create_notes_doc", mcp.Description("Create a Google Doc for meeting notes, titled after the event, and attach it (create action, default: false)")),
+		withAutoPaginate(),
+		withProfile(),
 	)
-	s.AddTool(eventTool, util.ErrorGuard(calendarEventHandler))
+	s.AddTool(eventTool, util.ErrorGuardNamed(eventTool.Name, calendarEventHandler))
 
 
+	// Respond to all pending invitations tool
+	respondAllTool := mcp.NewTool("calendar_respond_all",
+		mcp.WithDescription("Respond to all pending (needsAction) invitations in a time range at once, or list them with dry_run"),
+		mcp.WithString("response", mcp.Description("Response to apply: accepted, declined, or tentative (required unless dry_run is true)")),
+		mcp.WithString("time_min", mcp.Description("Start of the range in RFC3339 format (default: now)")),
+		mcp.WithString("time_max", mcp.Description("End of the range in RFC3339 format (default: 1 week from now)")),
+		mcp.WithBoolean("dry_run", mcp.Description("Only list the pending invitations without responding (default: false)")),
+		withProfile(),
+	)
+	s.AddTool(respondAllTool, util.ErrorGuardNamed(respondAllTool.Name, calendarRespondAllHandler))
+
 	// Find time slot tool
 	findTimeSlotTool := mcp.NewTool("calendar_find_time_slot",
 		mcp.WithDescription("Find available time slots based on room or guest availability"),
 		mcp.WithString("guests", mcp.Description("Comma-separated list of guest email addresses to check availability")),
+		mcp.WithString("optional_guests", mcp.Description("Comma-separated list of optional guest email addresses. Their busy times do not rule out a slot; each slot lists the optional guests who are busy")),
 		mcp.WithString("room", mcp.Description("Room to filter events by")),
+		mcp.WithString("room_calendar_ids", mcp.Description("Comma-separated list of room resource calendar IDs. When set, a slot is only offered if at least one of these rooms is free, and the free rooms are reported per slot")),
 		mcp.WithString("start_date", mcp.Required(), mcp.Description("Start date for searching slots in RFC3339 format")),
 		mcp.WithString("end_date", mcp.Required(), mcp.Description("End date for searching slots in RFC3339 format")),
 		mcp.WithNumber("duration_minutes", mcp.Required(), mcp.Description("Duration of the meeting in minutes")),
 		mcp.WithString("working_hours_start", mcp.Description("Start of working hours (e.g., '09:00', default: 09:00)")),
 		mcp.WithString("working_hours_end", mcp.Description("End of working hours (e.g., '17:00', default: 17:00)")),
+		mcp.WithString("working_hours_overrides", mcp.Description("Optional per-day working hours as JSON, e.g. {\"Fri\":\"09:00-13:00\"}. A day may have several comma-separated ranges for a split schedule, e.g. {\"Mon\":\"09:00-12:00,13:00-17:00\"}. Days without an override use working_hours_start/end")),
+		mcp.WithBoolean("merge_contiguous", mcp.Description("Report maximal free intervals (at least duration_minutes long) per day instead of fixed-length slots (default: false)")),
 		mcp.WithNumber("max_results", mcp.Description("Maximum number of time slots to return (default: 5)")),
+		withProfile(),
 	)
-	s.AddTool(findTimeSlotTool, util.ErrorGuard(calendarFindTimeSlotHandler))
+	s.AddTool(findTimeSlotTool, util.ErrorGuardNamed(findTimeSlotTool.Name, calendarFindTimeSlotHandler))
 
 	// Get busy times tool
 	getBusyTimesTool := mcp.NewTool("calendar_get_busy_times",
@@ -56,32 +93,157 @@ func RegisterCalendarTools(s *server.MCPServer) {
 		mcp.WithString("users", mcp.Description("Comma-separated list of user email addresses (leave empty for primary calendar only)")),
 		mcp.WithString("start_date", mcp.Required(), mcp.Description("Start date for the search in RFC3339 format")),
 		mcp.WithString("end_date", mcp.Required(), mcp.Description("End date for the search in RFC3339 format")),
+		withProfile(),
 	)
-	s.AddTool(getBusyTimesTool, util.ErrorGuard(calendarGetBusyTimesHandler))
-}
-
-var calendarService = sync.OnceValue(func() *calendar.Service {
-	ctx := context.Background()
+	s.AddTool(getBusyTimesTool, util.ErrorGuardNamed(getBusyTimesTool.Name, calendarGetBusyTimesHandler))
+
+	// Meeting time report tool
+	meetingTimeTool := mcp.NewTool("calendar_meeting_time",
+		mcp.WithDescription("Sum the time spent in meetings over a period, with total hours, meeting count, and a per-day breakdown. Skips all-day events and events you declined"),
+		mcp.WithString("start_date", mcp.Required(), mcp.Description("Start of the period in RFC3339 format")),
+		mcp.WithString("end_date", mcp.Required(), mcp.Description("End of the period in RFC3339 format")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar to report on (default: primary)")),
+		mcp.WithString("keyword", mcp.Description("Only count events whose title or description contains this text (case-insensitive)")),
+		mcp.WithString("attendee", mcp.Description("Only count events with this attendee email address")),
+		withProfile(),
+	)
+	s.AddTool(meetingTimeTool, util.ErrorGuardNamed(meetingTimeTool.Name, calendarMeetingTimeHandler))
+
+	// Room free/busy tool
+	roomFreeBusyTool := mcp.NewTool("calendar_room_free_busy",
+		mcp.WithDescription("Get busy intervals for a room resource calendar in a time window, to check whether the room is free"),
+		mcp.WithString("room_id", mcp.Required(), mcp.Description("Resource calendar ID of the room (e.g. c_1888...@resource.calendar.google.com)")),
+		mcp.WithString("start_time", mcp.Required(), mcp.Description("Start of the window in RFC3339 format")),
+		mcp.WithString("end_time", mcp.Required(), mcp.Description("End of the window in RFC3339 format")),
+		withProfile(),
+	)
+	s.AddTool(roomFreeBusyTool, util.ErrorGuardNamed(roomFreeBusyTool.Name, calendarRoomFreeBusyHandler))
+
+	// List contacts tool
+	listContactsTool := mcp.NewTool("calendar_list_contacts",
+		mcp.WithDescription("List the external people you met with in a time range, with the number of meetings and the most recent meeting date per person (e.g. for CRM sync)"),
+		mcp.WithString("time_min", mcp.Required(), mcp.Description("Start of the time range in RFC3339 format")),
+		mcp.WithString("time_max", mcp.Required(), mcp.Description("End of the time range in RFC3339 format")),
+		mcp.WithString("domain", mcp.Description("Internal email domain to exclude (e.g. example.com)")),
+		withProfile(),
+	)
+	s.AddTool(listContactsTool, util.ErrorGuardNamed(listContactsTool.Name, calendarListContactsHandler))
+
+	// Create calendar tool
+	createCalendarTool := mcp.NewTool("calendar_create_calendar",
+		mcp.WithDescription("Create a new secondary calendar. Pass the returned ID as calendar_id to calendar_event to manage its events"),
+		mcp.WithString("summary", mcp.Required(), mcp.Description("Title of the calendar")),
+		mcp.WithString("description", mcp.Description("Description of the calendar")),
+		mcp.WithString("time_zone", mcp.Description("IANA time zone of the calendar (e.g. Europe/Berlin, default: the account's time zone)")),
+		withProfile(),
+	)
+	s.AddTool(createCalendarTool, util.ErrorGuardNamed(createCalendarTool.Name, calendarCreateCalendarHandler))
+
+	// Delete calendar tool
+	deleteCalendarTool := mcp.NewTool("calendar_delete_calendar",
+		mcp.WithDescription("Permanently delete a secondary calendar and all of its events"),
+		mcp.WithString("calendar_id", mcp.Required(), mcp.Description("ID of the secondary calendar to delete")),
+		withConfirmation(),
+		withProfile(),
+	)
+	s.AddTool(deleteCalendarTool, util.ErrorGuardNamed(deleteCalendarTool.Name, calendarDeleteCalendarHandler))
+
+	// Calendar sharing tool
+	aclTool := mcp.NewTool("calendar_acl",
+		mcp.WithDescription("Manage who a calendar is shared with - list sharing rules, grant a user access, or revoke it"),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, grant, revoke")),
+		mcp.WithString("calendar_id", mcp.Description("ID of the calendar to manage (default: primary)")),
+		mcp.WithString("email", mcp.Description("Email address of the user (required for grant/revoke actions)")),
+		mcp.WithString("role", mcp.Description("Access level to grant: freeBusyReader, reader, writer, owner (grant action, default: reader)")),
+		withProfile(),
+	)
+	s.AddTool(aclTool, util.ErrorGuardNamed(aclTool.Name, calendarAclHandler))
+
+	// Default reminders tool
+	remindersTool := mcp.NewTool("calendar_default_reminders",
+		mcp.WithDescription("Get or set a calendar's default reminders, which apply to its events that have no reminders of their own, and its email notification settings"),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: get, set")),
+		mcp.WithString("calendar_id", mcp.Description("ID of the calendar (default: primary)")),
+		mcp.WithString("reminders", mcp.Description("Comma-separated method:minutes pairs, e.g. 'popup:10,email:60'; methods are popup and email, at most 5 reminders; empty string removes all default reminders (set action)")),
+		mcp.WithString("notifications", mcp.Description("Comma-separated notification types to receive by email: eventCreation, eventChange, eventCancellation, eventResponse, agenda; empty string turns them all off (set action, default: unchanged)")),
+		withProfile(),
+	)
+	s.AddTool(remindersTool, util.ErrorGuardNamed(remindersTool.Name, calendarDefaultRemindersHandler))
+
+	// Duplicate events tool
+	duplicatesTool := mcp.NewTool("calendar_find_duplicates",
+		mcp.WithDescription("Find duplicate events (same title, start and end) in a time range, and optionally delete all but the oldest copy of each"),
+		mcp.WithString("calendar_id", mcp.Description("ID of the calendar to check (default: primary)")),
+		mcp.WithString("time_min", mcp.Description("Start of the range in RFC3339 format (default: now)")),
+		mcp.WithString("time_max", mcp.Description("End of the range in RFC3339 format (default: 1 week from now)")),
+		mcp.WithBoolean("delete_duplicates", mcp.Description("Delete every copy except the oldest in each group (default: false). Requires confirmation: the first call lists the event IDs to delete and returns a confirmation_token")),
+		mcp.WithBoolean("dry_run", mcp.Description("Report which events would be deleted without deleting them (default: false)")),
+		withConfirmation(),
+		withProfile(),
+	)
+	s.AddTool(duplicatesTool, util.ErrorGuardNamed(duplicatesTool.Name, calendarFindDuplicatesHandler))
 
-	tokenFile := os.Getenv("GOOGLE_TOKEN_FILE")
-	if tokenFile == "" {
-		panic("GOOGLE_TOKEN_FILE environment variable must be set")
-	}
+	// Get settings tool
+	getSettingsTool := mcp.NewTool("calendar_get_settings",
+		mcp.WithDescription("Get the user's Google Calendar settings such as time zone, default event length, week start, and locale"),
+		withProfile(),
+	)
+	s.AddTool(getSettingsTool, util.ErrorGuardNamed(getSettingsTool.Name, calendarGetSettingsHandler))
 
-	credentialsFile := os.Getenv("GOOGLE_CREDENTIALS_FILE")
-	if credentialsFile == "" {
-		panic("GOOGLE_CREDENTIALS_FILE environment variable must be set")
-	}
+	// List colors tool
+	listColorsTool := mcp.NewTool("calendar_list_colors",
+		mcp.WithDescription("List Google Calendar's event and calendar color palettes, mapping each color ID to its background and foreground hex colors"),
+		withProfile(),
+	)
+	s.AddTool(listColorsTool, util.ErrorGuardNamed(listColorsTool.Name, calendarListColorsHandler))
+
+	// Meeting prep tool
+	meetingPrepTool := mcp.NewTool("calendar_meeting_prep",
+		mcp.WithDescription("Prepare for a meeting: get the event details plus a summary of recent emails from each external attendee"),
+		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event to prepare for")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar containing the event (default: primary)")),
+		mcp.WithNumber("days", mcp.Description("How many days back to search for emails (default: 30)")),
+		mcp.WithNumber("emails_per_attendee", mcp.Description("Maximum recent emails per attendee (default: 3)")),
+		mcp.WithBoolean("include_internal", mcp.Description("Also search emails from attendees in your own domain (default: false)")),
+		withProfile(),
+	)
+	s.AddTool(meetingPrepTool, util.ErrorGuardNamed(meetingPrepTool.Name, calendarMeetingPrepHandler))
+}
 
-	client := services.GoogleHttpClient(tokenFile, credentialsFile)
+var calendarServices = services.NewProfileCache(func(client *http.Client) (*calendar.Service, error) {
+	return calendar.NewService(context.Background(), option.WithHTTPClient(client))
+})
 
-	srv, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+// calendarService returns the Calendar service for the given credential profile ("" selects the default account).
+func calendarService(profile string) *calendar.Service {
+	srv, err := calendarServices.Get(profile)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create Calendar service: %v", err))
 	}
-
 	return srv
-})
+}
+
+// checkQuerySpan rejects time ranges longer than util.MaxCalendarSpanDays, so
+// an accidentally huge range does not page through years of events or
+// free/busy data and exhaust the API quota.
+func checkQuerySpan(start, end time.Time) error {
+	maxDays := util.MaxCalendarSpanDays()
+	if end.Sub(start) > time.Duration(maxDays)*24*time.Hour {
+		days := int(math.Ceil(end.Sub(start).Hours() / 24))
+		return fmt.Errorf("time range of %d days exceeds the maximum of %d days; narrow the range or raise CALENDAR_MAX_SPAN_DAYS", days, maxDays)
+	}
+	return nil
+}
+
+// calendarIDArg returns the calendar an event action targets, defaulting to
+// the user's primary calendar.
+func calendarIDArg(arguments map[string]interface{}) string {
+	calendarID, _ := arguments["calendar_id"].(string)
+	if calendarID == "" {
+		return "primary"
+	}
+	return calendarID
+}
 
 func calendarEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	action, _ := arguments["action"].(string)
@@ -89,31 +251,172 @@ func calendarEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult
 	switch action {
 	case "create":
 		return calendarCreateEventHandler(arguments)
+	case "get":
+		return calendarGetEventHandler(arguments)
 	case "update":
 		return calendarUpdateEventHandler(arguments)
+	case "duplicate":
+		return calendarDuplicateEventHandler(arguments)
+	case "move":
+		return calendarMoveEventHandler(arguments)
 	case "list":
 		return calendarListEventsHandler(arguments)
 	case "respond":
 		return calendarRespondToEventHandler(arguments)
+	case "check_conflicts":
+		return calendarCheckConflictsHandler(arguments)
 	default:
-		return mcp.NewToolResultError("Invalid action. Must be one of: create, update, list, respond"), nil
+		return mcp.NewToolResultError("Invalid action. Must be one of: create, get, update, duplicate, move, list, respond, check_conflicts"), nil
+	}
+}
+
+// calendarGetEventHandler returns an event's details together with the time
+// zone of the event itself, its organizer, and each attendee whose calendar
+// time zone is visible to the user.
+func calendarGetEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	eventID, _ := arguments["event_id"].(string)
+	if eventID == "" {
+		return mcp.NewToolResultError("event_id is required for get action"), nil
+	}
+
+	event, err := calendarService(profile).Events.Get(calendarID, eventID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"id":      event.Id,
+		"summary": event.Summary,
+		"start":   formatEventTime(event.Start),
+		"end":     formatEventTime(event.End),
+		"status":  event.Status,
+	}
+	if event.Description != "" {
+		result["description"] = event.Description
+	}
+	if event.Location != "" {
+		result["location"] = event.Location
+	}
+	if event.Start != nil && event.Start.TimeZone != "" {
+		result["startTimeZone"] = event.Start.TimeZone
+	}
+	if event.End != nil && event.End.TimeZone != "" {
+		result["endTimeZone"] = event.End.TimeZone
+	}
+
+	// Look up the default time zone of every participant's calendar
+	emails := make([]string, 0, len(event.Attendees)+1)
+	if event.Organizer != nil && event.Organizer.Email != "" {
+		emails = append(emails, event.Organizer.Email)
+	}
+	for _, attendee := range event.Attendees {
+		emails = append(emails, attendee.Email)
+	}
+	timeZones, _ := util.MapConcurrent(emails, 5, func(email string) (string, error) {
+		return calendarTimeZone(profile, email)
+	})
+	timeZoneByEmail := make(map[string]string, len(emails))
+	for i, email := range emails {
+		timeZoneByEmail[email] = timeZones[i]
+	}
+
+	if event.Organizer != nil {
+		organizer := map[string]string{
+			"email": event.Organizer.Email,
+		}
+		if tz := timeZoneByEmail[event.Organizer.Email]; tz != "" {
+			organizer["timeZone"] = tz
+		}
+		result["organizer"] = organizer
+	}
+
+	attendees := make([]map[string]string, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		attendeeInfo := map[string]string{
+			"email":          attendee.Email,
+			"responseStatus": attendee.ResponseStatus,
+		}
+		if attendee.Optional {
+			attendeeInfo["optional"] = "true"
+		}
+		if tz := timeZoneByEmail[attendee.Email]; tz != "" {
+			attendeeInfo["timeZone"] = tz
+		}
+		attendees = append(attendees, attendeeInfo)
+	}
+	result["attendees"] = attendees
+	if len(event.Attendees) > 0 {
+		result["responses"] = responseCounts(event.Attendees)
+	}
+
+	if len(event.Attachments) > 0 {
+		attachments := make([]map[string]string, 0, len(event.Attachments))
+		for _, attachment := range event.Attachments {
+			attachments = append(attachments, map[string]string{
+				"fileId":   attachment.FileId,
+				"fileUrl":  attachment.FileUrl,
+				"title":    attachment.Title,
+				"mimeType": attachment.MimeType,
+				"iconLink": attachment.IconLink,
+			})
+		}
+		result["attachments"] = attachments
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal event: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// calendarTimeZone returns the default time zone of the calendar identified by
+// id, preferring the user's calendar list entry and falling back to the
+// calendar's own metadata. Calendars the user cannot see return an error.
+func calendarTimeZone(profile string, id string) (string, error) {
+	if entry, err := calendarService(profile).CalendarList.Get(id).Do(); err == nil && entry.TimeZone != "" {
+		return entry.TimeZone, nil
 	}
+
+	cal, err := calendarService(profile).Calendars.Get(id).Do()
+	if err != nil {
+		return "", err
+	}
+	return cal.TimeZone, nil
 }
 
 func calendarCreateEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
 	summary, _ := arguments["summary"].(string)
 	description, _ := arguments["description"].(string)
 	startTimeStr, _ := arguments["start_time"].(string)
 	endTimeStr, _ := arguments["end_time"].(string)
 	attendeesStr, _ := arguments["attendees"].(string)
+	optionalStr, _ := arguments["optional_attendees"].(string)
+	organizerResponse, _ := arguments["organizer_response"].(string)
+	allDay, _ := arguments["all_day"].(bool)
+
+	// The calendar an event is created on becomes its organizer
+	if organizerCalendarID, _ := arguments["organizer_calendar_id"].(string); organizerCalendarID != "" {
+		if err := requireWriterAccess(profile, organizerCalendarID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		calendarID = organizerCalendarID
+	}
 
-	startTime, err := time.Parse(time.RFC3339, startTimeStr)
-	if err != nil {
-		return mcp.NewToolResultError("Invalid start_time format"), nil
+	var startTime, endTime time.Time
+	var err error
+	if allDay {
+		startTime, endTime, err = allDayRange(startTimeStr, endTimeStr)
+	} else {
+		startTime, endTime, err = util.ParseTimeRange(startTimeStr, endTimeStr)
 	}
-	endTime, err := time.Parse(time.RFC3339, endTimeStr)
 	if err != nil {
-		return mcp.NewToolResultError("Invalid end_time format"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	var attendees []*calendar.EventAttendee
@@ -122,6 +425,20 @@ func calendarCreateEventHandler(arguments map[string]interface{}) (*mcp.CallTool
 			attendees = append(attendees, &calendar.EventAttendee{Email: email})
 		}
 	}
+	attendees = withOptionalAttendees(attendees, optionalStr)
+
+	if organizerResponse != "" {
+		switch organizerResponse {
+		case "accepted", "tentative", "needsAction":
+		default:
+			return mcp.NewToolResultError("Invalid organizer_response. Must be one of: accepted, tentative, needsAction"), nil
+		}
+		self, err := authenticatedEmail(profile)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get your email address: %v", err)), nil
+		}
+		attendees = withAttendeeResponse(attendees, self, organizerResponse)
+	}
 
 	event := &calendar.Event{
 		Summary:     summary,
@@ -134,16 +451,273 @@ func calendarCreateEventHandler(arguments map[string]interface{}) (*mcp.CallTool
 		},
 		Attendees: attendees,
 	}
+	if colorID, _ := arguments["color_id"].(string); colorID != "" {
+		event.ColorId = colorID
+	}
+	if allDay {
+		event.Start = &calendar.EventDateTime{Date: startTime.Format("2006-01-02")}
+		event.End = &calendar.EventDateTime{Date: endTime.Format("2006-01-02")}
+	}
+
+	insertCall := calendarService(profile).Events.Insert(calendarID, event)
+
+	if fileIDs, _ := arguments["attachment_file_ids"].(string); fileIDs != "" {
+		attachments, err := driveAttachments(profile, fileIDs)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		event.Attachments = attachments
+		insertCall = insertCall.SupportsAttachments(true)
+	}
+
+	var notesDoc *drive.File
+	if createNotesDoc, _ := arguments["create_notes_doc"].(bool); createNotesDoc {
+		notesDoc, err = createGoogleDoc(profile, fmt.Sprintf("Notes - %s (%s)", summary, startTime.Format("2006-01-02")))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create notes doc: %v", err)), nil
+		}
+		event.Attachments = append(event.Attachments, driveFileAttachment(notesDoc))
+		insertCall = insertCall.SupportsAttachments(true)
+	}
+
+	createdEvent, err := insertCall.Do()
+	if err != nil {
+		message := fmt.Sprintf("failed to create event: %v", err)
+		// Do not leave the notes doc behind without its event
+		if notesDoc != nil {
+			if deleteErr := deleteDriveFile(profile, notesDoc.Id); deleteErr != nil {
+				message += fmt.Sprintf("\nThe notes doc could not be removed and remains at %s: %v", notesDoc.WebViewLink, deleteErr)
+			}
+		}
+		return mcp.NewToolResultError(message), nil
+	}
+
+	message := fmt.Sprintf("Successfully created event with ID: %s\nStart: %s\nEnd: %s", createdEvent.Id, eventTimeValue(createdEvent.Start), eventTimeValue(createdEvent.End))
+	if notesDoc != nil {
+		message += fmt.Sprintf("\nNotes doc: %s", notesDoc.WebViewLink)
+	}
+
+	return mcp.NewToolResultText(message), nil
+}
+
+// driveAttachments looks up each Drive file in a comma-separated list of IDs
+// and returns them as event attachments.
+func driveAttachments(profile string, fileIDs string) ([]*calendar.EventAttachment, error) {
+	attachments := make([]*calendar.EventAttachment, 0)
+	for _, fileID := range strings.Split(fileIDs, ",") {
+		fileID = strings.TrimSpace(fileID)
+		if fileID == "" {
+			continue
+		}
+		file, err := getDriveFile(profile, fileID)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, driveFileAttachment(file))
+	}
+	return attachments, nil
+}
+
+// driveFileAttachment converts a Drive file into an event attachment.
+func driveFileAttachment(file *drive.File) *calendar.EventAttachment {
+	return &calendar.EventAttachment{
+		FileId:   file.Id,
+		FileUrl:  file.WebViewLink,
+		Title:    file.Name,
+		MimeType: file.MimeType,
+		IconLink: file.IconLink,
+	}
+}
+
+// hasAttachment reports whether event already has the Drive file attached.
+func hasAttachment(event *calendar.Event, fileID string) bool {
+	for _, attachment := range event.Attachments {
+		if attachment.FileId == fileID {
+			return true
+		}
+	}
+	return false
+}
+
+// requireWriterAccess returns an error unless the user can create events on
+// the calendar, distinguishing missing permissions from lookup failures.
+func requireWriterAccess(profile string, calendarID string) error {
+	entry, err := calendarService(profile).CalendarList.Get(calendarID).Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusForbidden) {
+			return fmt.Errorf("permission denied: calendar %s is not in your calendar list or is not shared with you", calendarID)
+		}
+		return fmt.Errorf("failed to check access to calendar %s: %v", calendarID, err)
+	}
+	if entry.AccessRole != "writer" && entry.AccessRole != "owner" {
+		return fmt.Errorf("permission denied: you have %s access to calendar %s; writer or owner access is required to organize events on it", entry.AccessRole, calendarID)
+	}
+	return nil
+}
+
+// allDayRange parses the inclusive first and last days of an all-day event and
+// returns the start date and the exclusive end date the Calendar API expects:
+// an event on 2024-06-01 through 2024-06-03 ends on 2024-06-04. An empty end
+// means a single-day event.
+func allDayRange(startStr, endStr string) (time.Time, time.Time, error) {
+	start, err := parseEventDate(startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %v", err)
+	}
+	last := start
+	if endStr != "" {
+		last, err = parseEventDate(endStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %v", err)
+		}
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date %s is before start date %s", last.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+	return start, last.AddDate(0, 0, 1), nil
+}
+
+// parseEventDate parses a YYYY-MM-DD date, also accepting an RFC3339 time of
+// which only the date in the default timezone is used.
+func parseEventDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := util.ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a valid date (YYYY-MM-DD)", value)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// calendarDuplicateEventHandler inserts a copy of an existing event, optionally
+// moved to a new start time with the same duration.
+func calendarDuplicateEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	eventID, _ := arguments["event_id"].(string)
+	startTimeStr, _ := arguments["start_time"].(string)
+	if eventID == "" {
+		return mcp.NewToolResultError("event_id is required for duplicate action"), nil
+	}
+
+	event, err := calendarService(profile).Events.Get(calendarID, eventID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
+	}
+
+	// Strip identity and recurrence-instance fields so the copy is a new event
+	event.Id = ""
+	event.ICalUID = ""
+	event.Etag = ""
+	event.HtmlLink = ""
+	event.Created = ""
+	event.Updated = ""
+	event.Sequence = 0
+	event.RecurringEventId = ""
+	event.OriginalStartTime = nil
+	event.ConferenceData = nil
+	event.HangoutLink = ""
+
+	if startTimeStr != "" {
+		newStart, err := util.ParseTime(startTimeStr)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid start_time: %v", err)), nil
+		}
+		if err := shiftEventStart(event, newStart); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	createdEvent, err := calendarService(profile).Events.Insert(calendarID, event).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to duplicate event: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully duplicated event %s as %s (%s - %s)", eventID, createdEvent.Id, formatEventTime(createdEvent.Start), formatEventTime(createdEvent.End))), nil
+}
+
+func calendarMoveEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	eventID, _ := arguments["event_id"].(string)
+	destinationCalendarID, _ := arguments["destination_calendar_id"].(string)
+	if eventID == "" {
+		return mcp.NewToolResultError("event_id is required for move action"), nil
+	}
+	if destinationCalendarID == "" {
+		return mcp.NewToolResultError("destination_calendar_id is required for move action"), nil
+	}
 
-	createdEvent, err := calendarService().Events.Insert("primary", event).Do()
+	movedEvent, err := calendarService(profile).Events.Move(calendarID, eventID, destinationCalendarID).Do()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create event: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to move event: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully created event with ID: %s", createdEvent.Id)), nil
+	result := map[string]interface{}{
+		"id":           movedEvent.Id,
+		"summary":      movedEvent.Summary,
+		"start":        formatEventTime(movedEvent.Start),
+		"end":          formatEventTime(movedEvent.End),
+		"fromCalendar": calendarID,
+		"calendarId":   destinationCalendarID,
+		"htmlLink":     movedEvent.HtmlLink,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal event: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// shiftEventStart moves an event to newStart, keeping its duration. All-day
+// events are moved to newStart's date.
+func shiftEventStart(event *calendar.Event, newStart time.Time) error {
+	if event.Start == nil || event.End == nil {
+		return fmt.Errorf("event has no start or end time")
+	}
+
+	if event.Start.DateTime == "" {
+		start, err := time.Parse("2006-01-02", event.Start.Date)
+		if err != nil {
+			return fmt.Errorf("failed to parse event start date: %v", err)
+		}
+		end, err := time.Parse("2006-01-02", event.End.Date)
+		if err != nil {
+			return fmt.Errorf("failed to parse event end date: %v", err)
+		}
+		newDate := time.Date(newStart.Year(), newStart.Month(), newStart.Day(), 0, 0, 0, 0, time.UTC)
+		event.Start.Date = newDate.Format("2006-01-02")
+		event.End.Date = newDate.Add(end.Sub(start)).Format("2006-01-02")
+		return nil
+	}
+
+	start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse event start time: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, event.End.DateTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse event end time: %v", err)
+	}
+	event.Start.DateTime = newStart.Format(time.RFC3339)
+	event.End.DateTime = newStart.Add(end.Sub(start)).Format(time.RFC3339)
+	return nil
+}
+
+// calendarEventFields selects the fields of listed events for the configured
+// output verbosity.
+var calendarEventFields = util.FieldSet{
+	Terse:   []string{"id", "summary", "start"},
+	Verbose: []string{"location", "organizer", "attendeeCount", "htmlLink"},
 }
 
 func calendarListEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
 	timeMinStr, ok := arguments["time_min"].(string)
 	if !ok || timeMinStr == "" {
 		timeMinStr = time.Now().Format(time.RFC3339)
@@ -154,26 +728,35 @@ func calendarListEventsHandler(arguments map[string]interface{}) (*mcp.CallToolR
 		timeMaxStr = time.Now().AddDate(0, 0, 7).Format(time.RFC3339) // 1 week from now
 	}
 
+	timeMin, timeMax, err := util.ParseTimeRange(timeMinStr, timeMaxStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkQuerySpan(timeMin, timeMax); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	outputFormat, _ := arguments["output_format"].(string)
+	if outputFormat != "" && outputFormat != "yaml" && outputFormat != "csv" {
+		return mcp.NewToolResultError("Invalid output_format. Must be one of: yaml, csv"), nil
+	}
+
 	maxResults, ok := arguments["max_results"].(float64)
 	if !ok {
-		maxResults = 10
+		maxResults = float64(util.DefaultPageSizes().CalendarEvents)
 	}
 
-	events, err := calendarService().Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(timeMinStr).
-		TimeMax(timeMaxStr).
-		MaxResults(int64(maxResults)).
-		OrderBy("startTime").
-		Do()
+	showDeleted, _ := arguments["show_deleted"].(bool)
+	maxPages := maxPagesArg(arguments)
+
+	items, pageToken, pagesFetched, err := listEvents(profile, calendarID, timeMin, timeMax, int64(maxResults), maxPages, showDeleted)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list events: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	eventsList := make([]map[string]interface{}, 0)
 
-	for _, item := range events.Items {
+	for _, item := range items {
 		start, _ := time.Parse(time.RFC3339, item.Start.DateTime)
 		end, _ := time.Parse(time.RFC3339, item.End.DateTime)
 
@@ -187,14 +770,41 @@ func calendarListEventsHandler(arguments map[string]interface{}) (*mcp.CallToolR
 		if item.Description != "" {
 			eventInfo["description"] = item.Description
 		}
+		if showDeleted {
+			eventInfo["status"] = item.Status
+		}
+		if item.Location != "" {
+			eventInfo["location"] = item.Location
+		}
+		if item.Organizer != nil {
+			eventInfo["organizer"] = item.Organizer.Email
+		}
+		eventInfo["attendeeCount"] = len(item.Attendees)
+		eventInfo["htmlLink"] = item.HtmlLink
 
 		eventsList = append(eventsList, eventInfo)
 	}
+	calendarEventFields.Select(eventsList)
+
+	if outputFormat == "csv" {
+		columns := []string{"id", "summary", "start", "end", "description"}
+		if showDeleted {
+			columns = append(columns, "status")
+		}
+		csvResult, err := util.MapsToCSV(eventsList, calendarEventFields.Columns(columns...)...)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to format events as CSV: %v", err)), nil
+		}
+		return mcp.NewToolResultText(csvResult), nil
+	}
 
 	result := map[string]interface{}{
-		"count":  len(events.Items),
+		"count":  len(items),
 		"events": eventsList,
 	}
+	if maxPages > 1 {
+		addPaginationInfo(result, pagesFetched, pageToken)
+	}
 
 	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
@@ -204,218 +814,1995 @@ func calendarListEventsHandler(arguments map[string]interface{}) (*mcp.CallToolR
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-func calendarUpdateEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	eventID, _ := arguments["event_id"].(string)
-	summary, _ := arguments["summary"].(string)
-	description, _ := arguments["description"].(string)
-	startTimeStr, _ := arguments["start_time"].(string)
-	endTimeStr, _ := arguments["end_time"].(string)
-	attendeesStr, _ := arguments["attendees"].(string)
+func calendarMeetingTimeHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	startDateStr, _ := arguments["start_date"].(string)
+	endDateStr, _ := arguments["end_date"].(string)
+	keyword, _ := arguments["keyword"].(string)
+	attendee, _ := arguments["attendee"].(string)
 
-	event, err := calendarService().Events.Get("primary", eventID).Do()
+	startDate, endDate, err := util.ParseTimeRange(startDateStr, endDateStr)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-
-	if summary != "" {
-		event.Summary = summary
+	if err := checkQuerySpan(startDate, endDate); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	if description != "" {
-		event.Description = description
+
+	events, nextPageToken, _, err := listEvents(profile, calendarID, startDate, endDate, 250, maxPagesLimit, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	if startTimeStr != "" {
-		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+
+	keyword = strings.ToLower(keyword)
+	var total time.Duration
+	count := 0
+	perDay := make(map[string]time.Duration)
+	meetingsPerDay := make(map[string]int)
+	for _, event := range events {
+		if event.Start == nil || event.End == nil || event.Start.DateTime == "" || event.End.DateTime == "" {
+			continue
+		}
+		if selfResponseStatus(event) == "declined" {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(event.Summary+" "+event.Description), keyword) {
+			continue
+		}
+		if attendee != "" && !hasAttendee(event, attendee) {
+			continue
+		}
+
+		start, err := time.Parse(time.RFC3339, event.Start.DateTime)
 		if err != nil {
-			return mcp.NewToolResultError("Invalid start_time format"), nil
+			continue
 		}
-		event.Start.DateTime = startTime.Format(time.RFC3339)
-	}
-	if endTimeStr != "" {
-		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		end, err := time.Parse(time.RFC3339, event.End.DateTime)
 		if err != nil {
-			return mcp.NewToolResultError("Invalid end_time format"), nil
+			continue
 		}
-		event.End.DateTime = endTime.Format(time.RFC3339)
-	}
-	if attendeesStr != "" {
-		var attendees []*calendar.EventAttendee
-		for _, email := range strings.Split(attendeesStr, ",") {
-			attendees = append(attendees, &calendar.EventAttendee{Email: email})
+		// Only count the part of the event inside the period
+		if start.Before(startDate) {
+			start = startDate
+		}
+		if end.After(endDate) {
+			end = endDate
+		}
+		if !end.After(start) {
+			continue
 		}
-		event.Attendees = attendees
-	}
 
-	updatedEvent, err := calendarService().Events.Update("primary", eventID, event).Do()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to update event: %v", err)), nil
+		duration := end.Sub(start)
+		day := start.In(util.DefaultLocation()).Format("2006-01-02")
+		total += duration
+		count++
+		perDay[day] += duration
+		meetingsPerDay[day]++
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully updated event with ID: %s", updatedEvent.Id)), nil
-}
-
-func calendarRespondToEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	eventID, _ := arguments["event_id"].(string)
-	response, _ := arguments["response"].(string)
-
-	event, err := calendarService().Events.Get("primary", eventID).Do()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
+	days := make([]string, 0, len(perDay))
+	for day := range perDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	breakdown := make([]map[string]interface{}, 0, len(days))
+	for _, day := range days {
+		breakdown = append(breakdown, map[string]interface{}{
+			"date":     day,
+			"hours":    roundHours(perDay[day]),
+			"meetings": meetingsPerDay[day],
+		})
 	}
 
-	for _, attendee := range event.Attendees {
-		if attendee.Self {
-			attendee.ResponseStatus = response
-			break
-		}
+	result := map[string]interface{}{
+		"period": map[string]string{
+			"start": startDate.Format("2006-01-02 15:04"),
+			"end":   endDate.Format("2006-01-02 15:04"),
+		},
+		"totalHours":   roundHours(total),
+		"meetingCount": count,
+		"perDay":       breakdown,
+	}
+	if nextPageToken != "" {
+		result["truncated"] = true
 	}
 
-	_, err = calendarService().Events.Update("primary", eventID, event).Do()
+	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to update event response: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully responded '%s' to event with ID: %s", response, eventID)), nil
+	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-func calendarFindTimeSlotHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	guestsStr, _ := arguments["guests"].(string)
-	room, _ := arguments["room"].(string)
-	startDateStr, _ := arguments["start_date"].(string)
-	endDateStr, _ := arguments["end_date"].(string)
-	durationMinutes, _ := arguments["duration_minutes"].(float64)
-	workingHoursStart, _ := arguments["working_hours_start"].(string)
-	workingHoursEnd, _ := arguments["working_hours_end"].(string)
-	maxResults, _ := arguments["max_results"].(float64)
+// calendarCheckConflictsHandler lists the events on the calendar that overlap
+// a proposed time, and the busy periods of any given attendees that overlap it.
+// Declined events and events marked as free do not count as conflicts.
+func calendarCheckConflictsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	startTimeStr, _ := arguments["start_time"].(string)
+	endTimeStr, _ := arguments["end_time"].(string)
+	attendeesStr, _ := arguments["attendees"].(string)
 
-	if workingHoursStart == "" {
-		workingHoursStart = "09:00"
+	if startTimeStr == "" || endTimeStr == "" {
+		return mcp.NewToolResultError("start_time and end_time are required for 'check_conflicts' action"), nil
 	}
-	if workingHoursEnd == "" {
-		workingHoursEnd = "17:00"
+	startTime, endTime, err := util.ParseTimeRange(startTimeStr, endTimeStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	if maxResults <= 0 {
-		maxResults = 5
+
+	events, _, _, err := listEvents(profile, calendarID, startTime, endTime, 250, maxPagesLimit, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	conflicts := make([]map[string]interface{}, 0)
+	for _, event := range events {
+		if event.Transparency == "transparent" || selfResponseStatus(event) == "declined" {
+			continue
+		}
+		conflict := map[string]interface{}{
+			"id":      event.Id,
+			"summary": event.Summary,
+			"start":   formatEventTime(event.Start),
+			"end":     formatEventTime(event.End),
+		}
+		if event.Start != nil && event.Start.DateTime == "" {
+			conflict["allDay"] = true
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	result := map[string]interface{}{
+		"proposed": map[string]string{
+			"start": startTime.Format("2006-01-02 15:04"),
+			"end":   endTime.Format("2006-01-02 15:04"),
+		},
+		"calendar":     calendarID,
+		"hasConflicts": len(conflicts) > 0,
+		"conflicts":    conflicts,
+	}
+
+	if attendeesStr != "" {
+		attendees := make([]string, 0)
+		for _, email := range strings.Split(attendeesStr, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				attendees = append(attendees, email)
+			}
+		}
+
+		attendeeConflicts, err := attendeeBusyConflicts(profile, attendees, startTime, endTime)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, info := range attendeeConflicts {
+			if busy, _ := info["busy"].([]map[string]string); len(busy) > 0 {
+				result["hasConflicts"] = true
+			}
+		}
+		result["attendees"] = attendeeConflicts
 	}
 
-	startDate, err := time.Parse(time.RFC3339, startDateStr)
+	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
-		return mcp.NewToolResultError("Invalid start_date format"), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// attendeeBusyConflicts queries free/busy for the attendees and returns, for
+// each one, the busy periods overlapping the range. Event details of other
+// people's calendars are usually hidden, so only the times are reported, and
+// attendees whose free/busy cannot be read are marked with the reason.
+func attendeeBusyConflicts(profile string, attendees []string, startTime, endTime time.Time) ([]map[string]interface{}, error) {
+	items := make([]*calendar.FreeBusyRequestItem, 0, len(attendees))
+	for _, email := range attendees {
+		items = append(items, &calendar.FreeBusyRequestItem{Id: email})
 	}
-	endDate, err := time.Parse(time.RFC3339, endDateStr)
+
+	resp, err := calendarService(profile).Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin: startTime.Format(time.RFC3339),
+		TimeMax: endTime.Format(time.RFC3339),
+		Items:   items,
+	}).Do()
 	if err != nil {
-		return mcp.NewToolResultError("Invalid end_date format"), nil
+		return nil, fmt.Errorf("failed to query attendee free/busy: %v", err)
 	}
 
-	// Get all calendars to check (primary + guests)
-	calendarsToCheck := []string{"primary"}
-	if guestsStr != "" {
-		for _, guest := range strings.Split(guestsStr, ",") {
-			calendarsToCheck = append(calendarsToCheck, strings.TrimSpace(guest))
+	results := make([]map[string]interface{}, 0, len(attendees))
+	for _, email := range attendees {
+		info := map[string]interface{}{"email": email}
+		attendeeCalendar, ok := resp.Calendars[email]
+		if !ok || len(attendeeCalendar.Errors) > 0 {
+			reason := "no free/busy information returned"
+			if ok {
+				reasons := make([]string, 0, len(attendeeCalendar.Errors))
+				for _, e := range attendeeCalendar.Errors {
+					reasons = append(reasons, e.Reason)
+				}
+				reason = strings.Join(reasons, ", ")
+			}
+			info["unavailable"] = reason
+			results = append(results, info)
+			continue
 		}
+
+		busy := make([]map[string]string, 0, len(attendeeCalendar.Busy))
+		for _, period := range attendeeCalendar.Busy {
+			start, _ := time.Parse(time.RFC3339, period.Start)
+			end, _ := time.Parse(time.RFC3339, period.End)
+			busy = append(busy, map[string]string{
+				"start": start.Format("2006-01-02 15:04"),
+				"end":   end.Format("2006-01-02 15:04"),
+			})
+		}
+		info["busy"] = busy
+		results = append(results, info)
 	}
 
-	// Collect all busy times with details
-	allBusyTimes := make([]timeSlot, 0)
-	busyDetails := make([]busyTime, 0)
-	
-	for _, calendarId := range calendarsToCheck {
-		// Always use event listing to get details
-		events, err := calendarService().Events.List(calendarId).
-			ShowDeleted(false).
+	return results, nil
+}
+
+// hasAttendee reports whether email is among the event's attendees or is its organizer.
+func hasAttendee(event *calendar.Event, email string) bool {
+	if event.Organizer != nil && strings.EqualFold(event.Organizer.Email, email) {
+		return true
+	}
+	for _, attendee := range event.Attendees {
+		if strings.EqualFold(attendee.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// withOptionalAttendees marks the attendees listed in the comma-separated
+// optionalStr as optional, adding those that are not attendees yet.
+func withOptionalAttendees(attendees []*calendar.EventAttendee, optionalStr string) []*calendar.EventAttendee {
+	for _, email := range strings.Split(optionalStr, ",") {
+		if email = strings.TrimSpace(email); email == "" {
+			continue
+		}
+		found := false
+		for _, attendee := range attendees {
+			if strings.EqualFold(strings.TrimSpace(attendee.Email), email) {
+				attendee.Optional = true
+				found = true
+			}
+		}
+		if !found {
+			attendees = append(attendees, &calendar.EventAttendee{Email: email, Optional: true})
+		}
+	}
+	return attendees
+}
+
+// withAttendeeResponse sets the response status of the attendee with the
+// given email, adding them as an attendee if needed.
+func withAttendeeResponse(attendees []*calendar.EventAttendee, email string, response string) []*calendar.EventAttendee {
+	for _, attendee := range attendees {
+		if strings.EqualFold(strings.TrimSpace(attendee.Email), email) {
+			attendee.ResponseStatus = response
+			return attendees
+		}
+	}
+	return append(attendees, &calendar.EventAttendee{Email: email, ResponseStatus: response})
+}
+
+// responseCounts counts the attendees' responses, separately for required and
+// optional attendees.
+func responseCounts(attendees []*calendar.EventAttendee) map[string]map[string]int {
+	counts := map[string]map[string]int{
+		"required": {},
+		"optional": {},
+	}
+	for _, attendee := range attendees {
+		if attendee.Resource {
+			continue
+		}
+		group := "required"
+		if attendee.Optional {
+			group = "optional"
+		}
+		counts[group][attendee.ResponseStatus]++
+	}
+	return counts
+}
+
+// roundHours converts a duration to hours rounded to two decimal places.
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// listEvents lists the expanded events of a calendar in a time
+// range, following page tokens for up to maxPages pages. It returns the events,
+// the token of the next unread page (empty when the range was exhausted), and
+// the number of pages fetched.
+func listEvents(profile string, calendarID string, timeMin, timeMax time.Time, pageSize int64, maxPages int, showDeleted bool) ([]*calendar.Event, string, int, error) {
+	items := make([]*calendar.Event, 0)
+	pageToken := ""
+	pagesFetched := 0
+	for pagesFetched < maxPages {
+		listCall := calendarService(profile).Events.List(calendarID).
+			ShowDeleted(showDeleted).
 			SingleEvents(true).
-			TimeMin(startDate.Format(time.RFC3339)).
-			TimeMax(endDate.Format(time.RFC3339)).
-			OrderBy("startTime").
-			Do()
-		
+			TimeMin(timeMin.Format(time.RFC3339)).
+			TimeMax(timeMax.Format(time.RFC3339)).
+			MaxResults(pageSize).
+			OrderBy("startTime")
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		events, err := listCall.Do()
 		if err != nil {
-			continue // Skip this calendar if we can't access it
+			return nil, "", pagesFetched, fmt.Errorf("failed to list events: %v", err)
 		}
+		pagesFetched++
 
-		for _, event := range events.Items {
-			// Filter by room if specified
-			if room != "" && !strings.Contains(strings.ToLower(event.Location), strings.ToLower(room)) {
-				continue
+		items = append(items, events.Items...)
+		pageToken = events.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return items, pageToken, pagesFetched, nil
+}
+
+func calendarUpdateEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	eventID, _ := arguments["event_id"].(string)
+	summary, _ := arguments["summary"].(string)
+	description, _ := arguments["description"].(string)
+	startTimeStr, _ := arguments["start_time"].(string)
+	endTimeStr, _ := arguments["end_time"].(string)
+	attendeesStr, _ := arguments["attendees"].(string)
+	optionalStr, _ := arguments["optional_attendees"].(string)
+	preview, _ := arguments["preview"].(bool)
+
+	event, err := calendarService(profile).Events.Get(calendarID, eventID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
+	}
+	before := eventDiffFields(event)
+	beforeAttendees := attendeeEmails(event)
+
+	if summary != "" {
+		event.Summary = summary
+	}
+	if description != "" {
+		event.Description = description
+	}
+	if colorID, _ := arguments["color_id"].(string); colorID != "" {
+		event.ColorId = colorID
+	}
+	if startTimeStr != "" {
+		startTime, err := util.ParseTime(startTimeStr)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid start_time: %v", err)), nil
+		}
+		event.Start.DateTime = startTime.Format(time.RFC3339)
+	}
+	if endTimeStr != "" {
+		endTime, err := util.ParseTime(endTimeStr)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid end_time: %v", err)), nil
+		}
+		event.End.DateTime = endTime.Format(time.RFC3339)
+	}
+	if attendeesStr != "" {
+		var attendees []*calendar.EventAttendee
+		for _, email := range strings.Split(attendeesStr, ",") {
+			attendees = append(attendees, &calendar.EventAttendee{Email: email})
+		}
+		event.Attendees = attendees
+	}
+	event.Attendees = withOptionalAttendees(event.Attendees, optionalStr)
+
+	updateCall := calendarService(profile).Events.Update(calendarID, eventID, event)
+	if fileIDs, _ := arguments["attachment_file_ids"].(string); fileIDs != "" {
+		attachments, err := driveAttachments(profile, fileIDs)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, attachment := range attachments {
+			if !hasAttachment(event, attachment.FileId) {
+				event.Attachments = append(event.Attachments, attachment)
 			}
+		}
+		updateCall = updateCall.SupportsAttachments(true)
+	}
 
-			if event.Start.DateTime != "" && event.End.DateTime != "" {
-				start, _ := time.Parse(time.RFC3339, event.Start.DateTime)
-				end, _ := time.Parse(time.RFC3339, event.End.DateTime)
-				
-				allBusyTimes = append(allBusyTimes, timeSlot{Start: start, End: end})
-				
-				// Collect event details
-				organizer := ""
-				if event.Organizer != nil {
-					organizer = event.Organizer.Email
+	if preview {
+		return eventUpdatePreview(event, before, beforeAttendees)
+	}
+
+	updatedEvent, err := updateCall.Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update event: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully updated event with ID: %s\nStart: %s\nEnd: %s", updatedEvent.Id, eventTimeValue(updatedEvent.Start), eventTimeValue(updatedEvent.End))), nil
+}
+
+// eventDiffFieldNames lists the event fields an update preview compares, in
+// output order.
+var eventDiffFieldNames = []string{"summary", "description", "colorId", "start", "end", "attendees", "attachments"}
+
+// eventDiffFields returns the fields an update can change, each as a single
+// string for comparison.
+func eventDiffFields(event *calendar.Event) map[string]string {
+	attendees := make([]string, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		if attendee.Optional {
+			attendees = append(attendees, attendee.Email+" (optional)")
+		} else {
+			attendees = append(attendees, attendee.Email)
+		}
+	}
+	attachments := make([]string, 0, len(event.Attachments))
+	for _, attachment := range event.Attachments {
+		attachments = append(attachments, attachment.Title)
+	}
+
+	return map[string]string{
+		"summary":     event.Summary,
+		"description": event.Description,
+		"colorId":     event.ColorId,
+		"start":       eventTimeValue(event.Start),
+		"end":         eventTimeValue(event.End),
+		"attendees":   strings.Join(attendees, ", "),
+		"attachments": strings.Join(attachments, ", "),
+	}
+}
+
+// attendeeEmails returns the lowercased attendee email addresses of an event.
+func attendeeEmails(event *calendar.Event) []string {
+	emails := make([]string, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		emails = append(emails, strings.ToLower(strings.TrimSpace(attendee.Email)))
+	}
+	return emails
+}
+
+// eventUpdatePreview reports how the modified event differs from the fields
+// captured before the update, without saving it. Attendee changes also list
+// who would be added and removed, since an attendees argument replaces the
+// whole list.
+func eventUpdatePreview(event *calendar.Event, before map[string]string, beforeAttendees []string) (*mcp.CallToolResult, error) {
+	after := eventDiffFields(event)
+	afterAttendees := attendeeEmails(event)
+
+	changes := make([]map[string]interface{}, 0)
+	for _, field := range eventDiffFieldNames {
+		if before[field] == after[field] {
+			continue
+		}
+		change := map[string]interface{}{
+			"field":  field,
+			"before": before[field],
+			"after":  after[field],
+		}
+		if field == "attendees" {
+			removed := make([]string, 0)
+			for _, email := range beforeAttendees {
+				if !slices.Contains(afterAttendees, email) {
+					removed = append(removed, email)
 				}
-				
-				busyDetails = append(busyDetails, busyTime{
-					Start:      start,
-					End:        end,
-					Summary:    event.Summary,
-					Organizer:  organizer,
-					CalendarId: calendarId,
-				})
 			}
+			added := make([]string, 0)
+			for _, email := range afterAttendees {
+				if !slices.Contains(beforeAttendees, email) {
+					added = append(added, email)
+				}
+			}
+			if len(removed) > 0 {
+				change["removed"] = removed
+			}
+			if len(added) > 0 {
+				change["added"] = added
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	result := map[string]interface{}{
+		"preview": true,
+		"eventId": event.Id,
+		"changes": changes,
+		"message": "No changes were saved. Call again without preview to apply them.",
+	}
+	if len(changes) == 0 {
+		result["message"] = "The update would not change any fields."
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal preview: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func calendarRespondToEventHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	eventID, _ := arguments["event_id"].(string)
+	response, _ := arguments["response"].(string)
+
+	event, err := calendarService(profile).Events.Get(calendarID, eventID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
+	}
+
+	setSelfResponse(event, response)
+
+	_, err = calendarService(profile).Events.Update(calendarID, eventID, event).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update event response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully responded '%s' to event with ID: %s", response, eventID)), nil
+}
+
+// setSelfResponse sets the authenticated user's response on the event and
+// reports whether the user is an attendee.
+func setSelfResponse(event *calendar.Event, response string) bool {
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			attendee.ResponseStatus = response
+			return true
+		}
+	}
+	return false
+}
+
+// selfResponseStatus returns the authenticated user's response status, or "" if
+// the user is not an attendee.
+func selfResponseStatus(event *calendar.Event) string {
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			return attendee.ResponseStatus
+		}
+	}
+	return ""
+}
+
+func calendarRespondAllHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	response, _ := arguments["response"].(string)
+	dryRun, _ := arguments["dry_run"].(bool)
+
+	timeMinStr, ok := arguments["time_min"].(string)
+	if !ok || timeMinStr == "" {
+		timeMinStr = time.Now().Format(time.RFC3339)
+	}
+	timeMaxStr, ok := arguments["time_max"].(string)
+	if !ok || timeMaxStr == "" {
+		timeMaxStr = time.Now().AddDate(0, 0, 7).Format(time.RFC3339) // 1 week from now
+	}
+
+	if !dryRun && response != "accepted" && response != "declined" && response != "tentative" {
+		return mcp.NewToolResultError("Invalid response. Must be one of: accepted, declined, tentative"), nil
+	}
+
+	timeMin, timeMax, err := util.ParseTimeRange(timeMinStr, timeMaxStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkQuerySpan(timeMin, timeMax); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pending := make([]*calendar.Event, 0)
+	pageToken := ""
+	for {
+		listCall := calendarService(profile).Events.List("primary").
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(timeMin.Format(time.RFC3339)).
+			TimeMax(timeMax.Format(time.RFC3339)).
+			OrderBy("startTime")
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		events, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list events: %v", err)), nil
+		}
+
+		for _, event := range events.Items {
+			if selfResponseStatus(event) == "needsAction" {
+				pending = append(pending, event)
+			}
+		}
+
+		if events.NextPageToken == "" {
+			break
+		}
+		pageToken = events.NextPageToken
+	}
+
+	errs := make([]error, len(pending))
+	if !dryRun {
+		// Respond one at a time, carrying on past failures so the report
+		// covers every invitation
+		_, errs = util.RunBatch(pending, 1, func(event *calendar.Event) (*calendar.Event, error) {
+			setSelfResponse(event, response)
+			return calendarService(profile).Events.Update("primary", event.Id, event).Do()
+		})
+	}
+
+	eventsList := make([]map[string]interface{}, 0, len(pending))
+	failed := make([]string, 0)
+	skipped := make([]string, 0)
+	for i, event := range pending {
+		switch {
+		case errors.Is(errs[i], util.ErrBatchSkipped):
+			skipped = append(skipped, event.Id)
+			continue
+		case errs[i] != nil:
+			failed = append(failed, fmt.Sprintf("%s: %v", event.Id, errs[i]))
+			continue
+		}
+
+		eventInfo := map[string]interface{}{
+			"id":      event.Id,
+			"summary": event.Summary,
+			"start":   formatEventTime(event.Start),
+		}
+		if event.Organizer != nil {
+			eventInfo["organizer"] = event.Organizer.Email
+		}
+		eventsList = append(eventsList, eventInfo)
+	}
+
+	result := map[string]interface{}{
+		"dry_run": dryRun,
+		"count":   len(eventsList),
+		"events":  eventsList,
+	}
+	if !dryRun {
+		result["response"] = response
+	}
+	if len(failed) > 0 {
+		result["failed"] = failed
+	}
+	if len(skipped) > 0 {
+		result["skipped"] = skipped
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// eventTimeValue returns the exact RFC3339 time, or the date for all-day
+// events, so callers can see how their input was normalized.
+func eventTimeValue(eventTime *calendar.EventDateTime) string {
+	if eventTime == nil {
+		return ""
+	}
+	if eventTime.DateTime != "" {
+		return eventTime.DateTime
+	}
+	return eventTime.Date
+}
+
+// formatEventTime renders a timed or all-day event boundary for display.
+func formatEventTime(eventTime *calendar.EventDateTime) string {
+	if eventTime == nil {
+		return ""
+	}
+	if eventTime.DateTime == "" {
+		return eventTime.Date
+	}
+	t, err := time.Parse(time.RFC3339, eventTime.DateTime)
+	if err != nil {
+		return eventTime.DateTime
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func calendarFindTimeSlotHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	guestsStr, _ := arguments["guests"].(string)
+	optionalGuestsStr, _ := arguments["optional_guests"].(string)
+	room, _ := arguments["room"].(string)
+	roomCalendarIdsStr, _ := arguments["room_calendar_ids"].(string)
+	startDateStr, _ := arguments["start_date"].(string)
+	endDateStr, _ := arguments["end_date"].(string)
+	durationMinutes, _ := arguments["duration_minutes"].(float64)
+	workingHoursStart, _ := arguments["working_hours_start"].(string)
+	workingHoursEnd, _ := arguments["working_hours_end"].(string)
+	overridesStr, _ := arguments["working_hours_overrides"].(string)
+	mergeContiguous, _ := arguments["merge_contiguous"].(bool)
+	maxResults, _ := arguments["max_results"].(float64)
+
+	if workingHoursStart == "" {
+		workingHoursStart = "09:00"
+	}
+	if workingHoursEnd == "" {
+		workingHoursEnd = "17:00"
+	}
+	if maxResults <= 0 {
+		maxResults = float64(util.DefaultPageSizes().CalendarSlots)
+	}
+
+	if _, err := parseWorkingRange(workingHoursStart + "-" + workingHoursEnd); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid working_hours_start/working_hours_end: %v", err)), nil
+	}
+	overrides, err := parseWorkingHoursOverrides(overridesStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	startDate, endDate, err := util.ParseTimeRange(startDateStr, endDateStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkQuerySpan(startDate, endDate); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Get all calendars to check (primary + guests)
+	calendarsToCheck := []string{"primary"}
+	if guestsStr != "" {
+		for _, guest := range strings.Split(guestsStr, ",") {
+			calendarsToCheck = append(calendarsToCheck, strings.TrimSpace(guest))
+		}
+	}
+	optionalGuests := make([]string, 0)
+	isOptional := make(map[string]bool)
+	for _, guest := range strings.Split(optionalGuestsStr, ",") {
+		// A guest who is also required stays required
+		if guest = strings.TrimSpace(guest); guest != "" && !slices.Contains(calendarsToCheck, guest) {
+			optionalGuests = append(optionalGuests, guest)
+			isOptional[guest] = true
+			calendarsToCheck = append(calendarsToCheck, guest)
+		}
+	}
+
+	// Collect all busy times with details. Optional guests' busy times are
+	// kept apart so they never rule out a slot
+	allBusyTimes := make([]timeSlot, 0)
+	optionalBusyTimes := make(map[string][]timeSlot)
+	busyDetails := make([]busyTime, 0)
+	
+	for _, calendarId := range calendarsToCheck {
+		// Always use event listing to get details
+		events, err := calendarService(profile).Events.List(calendarId).
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(startDate.Format(time.RFC3339)).
+			TimeMax(endDate.Format(time.RFC3339)).
+			OrderBy("startTime").
+			Do()
+		
+		if err != nil {
+			continue // Skip this calendar if we can't access it
+		}
+
+		for _, event := range events.Items {
+			// Filter by room if specified
+			if room != "" && !strings.Contains(strings.ToLower(event.Location), strings.ToLower(room)) {
+				continue
+			}
+
+			if event.Start.DateTime != "" && event.End.DateTime != "" {
+				start, _ := time.Parse(time.RFC3339, event.Start.DateTime)
+				end, _ := time.Parse(time.RFC3339, event.End.DateTime)
+				
+				if isOptional[calendarId] {
+					optionalBusyTimes[calendarId] = append(optionalBusyTimes[calendarId], timeSlot{Start: start, End: end})
+				} else {
+					allBusyTimes = append(allBusyTimes, timeSlot{Start: start, End: end})
+				}
+				
+				// Collect event details
+				organizer := ""
+				if event.Organizer != nil {
+					organizer = event.Organizer.Email
+				}
+				
+				busyDetails = append(busyDetails, busyTime{
+					Start:      start,
+					End:        end,
+					Summary:    event.Summary,
+					Organizer:  organizer,
+					CalendarId: calendarId,
+					Optional:   isOptional[calendarId],
+				})
+			}
+		}
+	}
+
+	// Merge overlapping busy times
+	mergedBusyTimes := mergeTimeSlots(allBusyTimes)
+
+	// Find available slots
+	findSlots := findAvailableSlots
+	if mergeContiguous {
+		findSlots = findFreeIntervals
+	}
+
+	roomIds := make([]string, 0)
+	for _, roomId := range strings.Split(roomCalendarIdsStr, ",") {
+		if roomId = strings.TrimSpace(roomId); roomId != "" {
+			roomIds = append(roomIds, roomId)
+		}
+	}
+
+	var availableSlots []timeSlot
+	slotRooms := make(map[time.Time][]string)
+	if len(roomIds) == 0 {
+		availableSlots = findSlots(
+			startDate,
+			endDate,
+			mergedBusyTimes,
+			time.Duration(durationMinutes)*time.Minute,
+			workingHoursStart,
+			workingHoursEnd,
+			overrides,
+			int(maxResults),
+		)
+	} else {
+		roomBusyTimes, err := roomBusySlots(profile, roomIds, startDate, endDate)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Search each room separately, then combine the slots by start time so
+		// every slot lists the rooms that are free for it
+		slotsByStart := make(map[time.Time]timeSlot)
+		for _, roomId := range roomIds {
+			busy := append(append([]timeSlot{}, allBusyTimes...), roomBusyTimes[roomId]...)
+			roomSlots := findSlots(
+				startDate,
+				endDate,
+				mergeTimeSlots(busy),
+				time.Duration(durationMinutes)*time.Minute,
+				workingHoursStart,
+				workingHoursEnd,
+				overrides,
+				int(maxResults),
+			)
+			for _, slot := range roomSlots {
+				if existing, ok := slotsByStart[slot.Start]; !ok || slot.End.Before(existing.End) {
+					slotsByStart[slot.Start] = slot
+				}
+				slotRooms[slot.Start] = append(slotRooms[slot.Start], roomId)
+			}
+		}
+
+		availableSlots = make([]timeSlot, 0, len(slotsByStart))
+		for _, slot := range slotsByStart {
+			availableSlots = append(availableSlots, slot)
+		}
+		sort.Slice(availableSlots, func(i, j int) bool {
+			return availableSlots[i].Start.Before(availableSlots[j].Start)
+		})
+		if len(availableSlots) > int(maxResults) {
+			availableSlots = availableSlots[:int(maxResults)]
+		}
+	}
+
+	// Format results
+	result := map[string]interface{}{
+		"available_slots": make([]map[string]string, 0),
+		"duration_minutes": durationMinutes,
+		"busy_times": make([]map[string]string, 0),
+	}
+
+	if guestsStr != "" {
+		result["guests_checked"] = guestsStr
+	}
+	if len(optionalGuests) > 0 {
+		result["optional_guests_checked"] = strings.Join(optionalGuests, ", ")
+	}
+	if room != "" {
+		result["room_filter"] = room
+	}
+	if len(roomIds) > 0 {
+		result["rooms_checked"] = roomIds
+	}
+	if overridesStr != "" {
+		result["working_hours_overrides"] = overridesStr
+	}
+
+	// Add available slots
+	for _, slot := range availableSlots {
+		slotInfo := map[string]string{
+			"start": slot.Start.Format("2006-01-02 15:04"),
+			"end":   slot.End.Format("2006-01-02 15:04"),
+			"day":   slot.Start.Format("Monday"),
+		}
+		if mergeContiguous {
+			slotInfo["minutes"] = fmt.Sprintf("%d", int(slot.End.Sub(slot.Start).Minutes()))
+		}
+		if rooms, ok := slotRooms[slot.Start]; ok {
+			slotInfo["rooms"] = strings.Join(rooms, ", ")
+		}
+		if len(optionalGuests) > 0 {
+			busyGuests := make([]string, 0)
+			for _, guest := range optionalGuests {
+				if overlapsAny(slot, optionalBusyTimes[guest]) {
+					busyGuests = append(busyGuests, guest)
+				}
+			}
+			if len(busyGuests) > 0 {
+				slotInfo["optional_guests_busy"] = strings.Join(busyGuests, ", ")
+			}
+		}
+		result["available_slots"] = append(result["available_slots"].([]map[string]string), slotInfo)
+	}
+
+	// Add busy time details
+	for _, busy := range busyDetails {
+		busyInfo := map[string]string{
+			"start":     busy.Start.Format("2006-01-02 15:04"),
+			"end":       busy.End.Format("2006-01-02 15:04"),
+			"summary":   busy.Summary,
+			"organizer": busy.Organizer,
+		}
+		
+		// Add calendar info to identify whose calendar it is
+		if busy.CalendarId == "primary" {
+			busyInfo["calendar"] = "Your calendar"
+		} else if busy.Optional {
+			busyInfo["calendar"] = busy.CalendarId + " (optional)"
+		} else {
+			busyInfo["calendar"] = busy.CalendarId
+		}
+		
+		result["busy_times"] = append(result["busy_times"].([]map[string]string), busyInfo)
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+type timeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+type busyTime struct {
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Organizer   string
+	CalendarId  string
+	Optional    bool
+}
+
+// overlapsAny reports whether slot overlaps any of the busy periods.
+func overlapsAny(slot timeSlot, busy []timeSlot) bool {
+	for _, period := range busy {
+		if period.Start.Before(slot.End) && period.End.After(slot.Start) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeTimeSlots(slots []timeSlot) []timeSlot {
+	if len(slots) == 0 {
+		return slots
+	}
+
+	// Sort slots by start time
+	for i := 0; i < len(slots); i++ {
+		for j := i + 1; j < len(slots); j++ {
+			if slots[i].Start.After(slots[j].Start) {
+				slots[i], slots[j] = slots[j], slots[i]
+			}
+		}
+	}
+
+	merged := []timeSlot{slots[0]}
+	for i := 1; i < len(slots); i++ {
+		last := &merged[len(merged)-1]
+		if slots[i].Start.Before(last.End) || slots[i].Start.Equal(last.End) {
+			// Overlapping or adjacent, merge them
+			if slots[i].End.After(last.End) {
+				last.End = slots[i].End
+			}
+		} else {
+			// No overlap, add as new slot
+			merged = append(merged, slots[i])
+		}
+	}
+
+	return merged
+}
+
+// workingHours is a start/end pair in "HH:MM" form.
+type workingHours struct {
+	Start string
+	End   string
+}
+
+// parseWorkingHoursOverrides parses a JSON object mapping weekday names
+// ("Fri", "Friday", case-insensitive) to one or more comma-separated
+// "HH:MM-HH:MM" ranges, returned sorted by start time.
+func parseWorkingHoursOverrides(overridesStr string) (map[time.Weekday][]workingHours, error) {
+	overrides := make(map[time.Weekday][]workingHours)
+	if overridesStr == "" {
+		return overrides, nil
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(overridesStr), &raw); err != nil {
+		return nil, fmt.Errorf("invalid working_hours_overrides: must be a JSON object like {\"Fri\":\"09:00-13:00\"}: %v", err)
+	}
+
+	for day, hours := range raw {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return nil, fmt.Errorf("invalid working_hours_overrides: unknown day %q", day)
+		}
+		ranges := make([]workingHours, 0)
+		for _, value := range strings.Split(hours, ",") {
+			workingRange, err := parseWorkingRange(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid working_hours_overrides: hours for %s: %v", day, err)
+			}
+			ranges = append(ranges, workingRange)
+		}
+		// Zero-padded HH:MM strings sort chronologically
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+		for i := 1; i < len(ranges); i++ {
+			if ranges[i].Start < ranges[i-1].End {
+				return nil, fmt.Errorf("invalid working_hours_overrides: hours for %s overlap: %s-%s and %s-%s", day, ranges[i-1].Start, ranges[i-1].End, ranges[i].Start, ranges[i].End)
+			}
+		}
+		overrides[weekday] = ranges
+	}
+
+	return overrides, nil
+}
+
+// parseWorkingRange parses an "HH:MM-HH:MM" range whose start is before its
+// end, normalizing both times to zero-padded HH:MM.
+func parseWorkingRange(value string) (workingHours, error) {
+	start, end, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return workingHours{}, fmt.Errorf("%q must be in HH:MM-HH:MM format", value)
+	}
+	startHour, startMin, err := parseClock(start)
+	if err != nil {
+		return workingHours{}, err
+	}
+	endHour, endMin, err := parseClock(end)
+	if err != nil {
+		return workingHours{}, err
+	}
+	if startHour*60+startMin >= endHour*60+endMin {
+		return workingHours{}, fmt.Errorf("%q must start before it ends", value)
+	}
+	return workingHours{
+		Start: fmt.Sprintf("%02d:%02d", startHour, startMin),
+		End:   fmt.Sprintf("%02d:%02d", endHour, endMin),
+	}, nil
+}
+
+// parseClock parses a 24-hour "HH:MM" time of day.
+func parseClock(value string) (hour, minute int, err error) {
+	value = strings.TrimSpace(value)
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q: must be HH:MM in 24-hour format, e.g. 09:30", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if day == name || day == name[:3] {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// workingWindows returns the working-hours windows for the day of currentDate,
+// in order and clamped to [startDate, endDate]. A split schedule yields several
+// windows; days that should be skipped yield none. The hours must already have
+// been validated.
+func workingWindows(currentDate, startDate, endDate time.Time, workStart, workEnd string, overrides map[time.Weekday][]workingHours) []timeSlot {
+	// Skip weekends unless the user has explicit hours for that day
+	dayHours, hasOverride := overrides[currentDate.Weekday()]
+	if !hasOverride && (currentDate.Weekday() == time.Saturday || currentDate.Weekday() == time.Sunday) {
+		return nil
+	}
+
+	// Parse working hours, preferring the per-day override if any
+	if !hasOverride {
+		dayHours = []workingHours{{Start: workStart, End: workEnd}}
+	}
+
+	windows := make([]timeSlot, 0, len(dayHours))
+	for _, hours := range dayHours {
+		workStartHour, workStartMin, _ := parseClock(hours.Start)
+		workEndHour, workEndMin, _ := parseClock(hours.End)
+
+		// Set working hours for current day
+		windowStart := time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), workStartHour, workStartMin, 0, 0, currentDate.Location())
+		windowEnd := time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), workEndHour, workEndMin, 0, 0, currentDate.Location())
+
+		// Ensure we don't go before the start date
+		if windowStart.Before(startDate) {
+			windowStart = startDate
+		}
+		// Ensure we don't go after the end date
+		if windowEnd.After(endDate) {
+			windowEnd = endDate
+		}
+		if windowStart.Before(windowEnd) {
+			windows = append(windows, timeSlot{Start: windowStart, End: windowEnd})
+		}
+	}
+
+	return windows
+}
+
+func findAvailableSlots(startDate, endDate time.Time, busySlots []timeSlot, duration time.Duration, workStart, workEnd string, overrides map[time.Weekday][]workingHours, maxResults int) []timeSlot {
+	availableSlots := make([]timeSlot, 0)
+
+	currentDate := startDate
+	for currentDate.Before(endDate) && len(availableSlots) < maxResults {
+		for _, window := range workingWindows(currentDate, startDate, endDate, workStart, workEnd, overrides) {
+			if len(availableSlots) >= maxResults {
+				break
+			}
+			dayStart, dayEnd := window.Start, window.End
+
+			// Find free slots in this window
+			currentTime := dayStart
+			for currentTime.Add(duration).Before(dayEnd) || currentTime.Add(duration).Equal(dayEnd) {
+				slotEnd := currentTime.Add(duration)
+			
+				// Check if this slot conflicts with any busy time
+				isAvailable := true
+				for _, busySlot := range busySlots {
+					if (currentTime.Before(busySlot.End) && slotEnd.After(busySlot.Start)) {
+						// Conflict found
+						isAvailable = false
+						// Move current time to the end of the busy slot
+						if busySlot.End.After(currentTime) {
+							currentTime = busySlot.End
+						}
+						break
+					}
+				}
+
+				if isAvailable {
+					availableSlots = append(availableSlots, timeSlot{Start: currentTime, End: slotEnd})
+					if len(availableSlots) >= maxResults {
+						break
+					}
+					// Move to next potential slot (30 minute increments)
+					currentTime = currentTime.Add(30 * time.Minute)
+				}
+			}
+		}
+
+		currentDate = currentDate.AddDate(0, 0, 1)
+	}
+
+	return availableSlots
+}
+
+// findFreeIntervals returns the maximal free intervals of at least duration
+// within each day's working hours. busySlots must be sorted and merged.
+func findFreeIntervals(startDate, endDate time.Time, busySlots []timeSlot, duration time.Duration, workStart, workEnd string, overrides map[time.Weekday][]workingHours, maxResults int) []timeSlot {
+	freeIntervals := make([]timeSlot, 0)
+
+	currentDate := startDate
+	for currentDate.Before(endDate) && len(freeIntervals) < maxResults {
+		for _, window := range workingWindows(currentDate, startDate, endDate, workStart, workEnd, overrides) {
+			dayStart, dayEnd := window.Start, window.End
+
+			// Walk the busy slots, emitting the gaps between them
+			freeStart := dayStart
+			for _, busySlot := range busySlots {
+				if !busySlot.End.After(freeStart) {
+					continue
+				}
+				if !busySlot.Start.Before(dayEnd) {
+					break
+				}
+				if busySlot.Start.Sub(freeStart) >= duration {
+					freeIntervals = append(freeIntervals, timeSlot{Start: freeStart, End: busySlot.Start})
+				}
+				freeStart = busySlot.End
+			}
+			if dayEnd.Sub(freeStart) >= duration {
+				freeIntervals = append(freeIntervals, timeSlot{Start: freeStart, End: dayEnd})
+			}
+		}
+
+		currentDate = currentDate.AddDate(0, 0, 1)
+	}
+
+	if len(freeIntervals) > maxResults {
+		freeIntervals = freeIntervals[:maxResults]
+	}
+
+	return freeIntervals
+}
+
+func calendarGetBusyTimesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	usersStr, _ := arguments["users"].(string)
+	startDateStr, _ := arguments["start_date"].(string)
+	endDateStr, _ := arguments["end_date"].(string)
+
+	startDate, endDate, err := util.ParseTimeRange(startDateStr, endDateStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkQuerySpan(startDate, endDate); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Determine calendars to check
+	calendarsToCheck := []string{"primary"}
+	if usersStr != "" {
+		calendarsToCheck = []string{}
+		for _, user := range strings.Split(usersStr, ",") {
+			calendarsToCheck = append(calendarsToCheck, strings.TrimSpace(user))
+		}
+	}
+
+	// Collect busy times from all calendars
+	busyDetails := make([]busyTime, 0)
+	
+	for _, calendarId := range calendarsToCheck {
+		events, err := calendarService(profile).Events.List(calendarId).
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(startDate.Format(time.RFC3339)).
+			TimeMax(endDate.Format(time.RFC3339)).
+			OrderBy("startTime").
+			Do()
+		
+		if err != nil {
+			// Skip calendars we can't access but include error info
+			busyDetails = append(busyDetails, busyTime{
+				Summary:    fmt.Sprintf("Error accessing calendar: %s", err.Error()),
+				CalendarId: calendarId,
+			})
+			continue
+		}
+
+		for _, event := range events.Items {
+			if event.Start.DateTime != "" && event.End.DateTime != "" {
+				start, _ := time.Parse(time.RFC3339, event.Start.DateTime)
+				end, _ := time.Parse(time.RFC3339, event.End.DateTime)
+				
+				// Get organizer info
+				organizer := ""
+				if event.Organizer != nil {
+					if event.Organizer.DisplayName != "" {
+						organizer = event.Organizer.DisplayName
+					} else {
+						organizer = event.Organizer.Email
+					}
+				}
+				
+				busyDetails = append(busyDetails, busyTime{
+					Start:      start,
+					End:        end,
+					Summary:    event.Summary,
+					Organizer:  organizer,
+					CalendarId: calendarId,
+				})
+			}
+		}
+	}
+
+	// Sort busy times by start time
+	for i := 0; i < len(busyDetails); i++ {
+		for j := i + 1; j < len(busyDetails); j++ {
+			if busyDetails[i].Start.After(busyDetails[j].Start) {
+				busyDetails[i], busyDetails[j] = busyDetails[j], busyDetails[i]
+			}
+		}
+	}
+
+	// Format results
+	result := map[string]interface{}{
+		"period": map[string]string{
+			"start": startDate.Format("2006-01-02 15:04"),
+			"end":   endDate.Format("2006-01-02 15:04"),
+		},
+		"calendars_checked": calendarsToCheck,
+		"busy_times":        make([]map[string]interface{}, 0),
+		"total_busy_times":  len(busyDetails),
+	}
+
+	// Add busy time details
+	for _, busy := range busyDetails {
+		busyInfo := map[string]interface{}{
+			"start":     busy.Start.Format("2006-01-02 15:04"),
+			"end":       busy.End.Format("2006-01-02 15:04"),
+			"calendar":  busy.CalendarId,
+			"summary":   busy.Summary,
+			"organizer": busy.Organizer,
+			"day":       busy.Start.Format("Monday"),
+		}
+
+		// Calculate duration
+		duration := busy.End.Sub(busy.Start)
+		busyInfo["duration_minutes"] = int(duration.Minutes())
+		
+		result["busy_times"] = append(result["busy_times"].([]map[string]interface{}), busyInfo)
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// roomBusySlots queries FreeBusy for the given room resource calendars and
+// returns their busy periods keyed by room ID.
+func roomBusySlots(profile string, roomIds []string, startDate, endDate time.Time) (map[string][]timeSlot, error) {
+	items := make([]*calendar.FreeBusyRequestItem, 0, len(roomIds))
+	for _, roomId := range roomIds {
+		items = append(items, &calendar.FreeBusyRequestItem{Id: roomId})
+	}
+
+	resp, err := calendarService(profile).Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin: startDate.Format(time.RFC3339),
+		TimeMax: endDate.Format(time.RFC3339),
+		Items:   items,
+	}).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query room free/busy: %v", err)
+	}
+
+	busySlots := make(map[string][]timeSlot, len(roomIds))
+	for _, roomId := range roomIds {
+		roomCalendar, ok := resp.Calendars[roomId]
+		if !ok {
+			return nil, fmt.Errorf("no free/busy information returned for room: %s", roomId)
+		}
+		if len(roomCalendar.Errors) > 0 {
+			reasons := make([]string, 0, len(roomCalendar.Errors))
+			for _, e := range roomCalendar.Errors {
+				reasons = append(reasons, e.Reason)
+			}
+			return nil, fmt.Errorf("failed to read room calendar %s: %s", roomId, strings.Join(reasons, ", "))
+		}
+
+		for _, period := range roomCalendar.Busy {
+			start, _ := time.Parse(time.RFC3339, period.Start)
+			end, _ := time.Parse(time.RFC3339, period.End)
+			busySlots[roomId] = append(busySlots[roomId], timeSlot{Start: start, End: end})
+		}
+	}
+
+	return busySlots, nil
+}
+
+func calendarRoomFreeBusyHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	roomID, _ := arguments["room_id"].(string)
+	startTimeStr, _ := arguments["start_time"].(string)
+	endTimeStr, _ := arguments["end_time"].(string)
+
+	if roomID == "" {
+		return mcp.NewToolResultError("room_id is required"), nil
+	}
+
+	startTime, endTime, err := util.ParseTimeRange(startTimeStr, endTimeStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkQuerySpan(startTime, endTime); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	busySlots, err := roomBusySlots(profile, []string{roomID}, startTime, endTime)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	busyTimes := make([]map[string]string, 0, len(busySlots[roomID]))
+	for _, slot := range busySlots[roomID] {
+		busyTimes = append(busyTimes, map[string]string{
+			"start": slot.Start.In(startTime.Location()).Format("2006-01-02 15:04"),
+			"end":   slot.End.In(startTime.Location()).Format("2006-01-02 15:04"),
+		})
+	}
+
+	result := map[string]interface{}{
+		"room": roomID,
+		"period": map[string]string{
+			"start": startTime.Format("2006-01-02 15:04"),
+			"end":   endTime.Format("2006-01-02 15:04"),
+		},
+		"is_free":    len(busyTimes) == 0,
+		"busy_times": busyTimes,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// calendarContactFields selects the fields of listed contacts for the
+// configured output verbosity.
+var calendarContactFields = util.FieldSet{
+	Terse: []string{"email", "name"},
+}
+
+func calendarListContactsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	timeMinStr, _ := arguments["time_min"].(string)
+	timeMaxStr, _ := arguments["time_max"].(string)
+	domain, _ := arguments["domain"].(string)
+	domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+
+	timeMin, timeMax, err := util.ParseTimeRange(timeMinStr, timeMaxStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkQuerySpan(timeMin, timeMax); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	events, pageToken, _, err := listEvents(profile, "primary", timeMin, timeMax, 250, maxPagesLimit, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	type contact struct {
+		meetings    int
+		lastMeeting time.Time
+		name        string
+	}
+	contacts := make(map[string]*contact)
+	for _, event := range events {
+		if event.Status == "cancelled" || event.Start == nil {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+		if err != nil {
+			start, _ = time.Parse("2006-01-02", event.Start.Date)
+		}
+
+		for _, attendee := range event.Attendees {
+			email := strings.ToLower(attendee.Email)
+			if attendee.Self || attendee.Resource || email == "" {
+				continue
+			}
+			if domain != "" && strings.HasSuffix(email, "@"+domain) {
+				continue
+			}
+
+			c, ok := contacts[email]
+			if !ok {
+				c = &contact{}
+				contacts[email] = c
+			}
+			c.meetings++
+			if start.After(c.lastMeeting) {
+				c.lastMeeting = start
+			}
+			if attendee.DisplayName != "" {
+				c.name = attendee.DisplayName
+			}
+		}
+	}
+
+	emails := make([]string, 0, len(contacts))
+	for email := range contacts {
+		emails = append(emails, email)
+	}
+	sort.Slice(emails, func(i, j int) bool {
+		if contacts[emails[i]].meetings != contacts[emails[j]].meetings {
+			return contacts[emails[i]].meetings > contacts[emails[j]].meetings
+		}
+		return emails[i] < emails[j]
+	})
+
+	contactList := make([]map[string]interface{}, 0, len(emails))
+	for _, email := range emails {
+		c := contacts[email]
+		contactInfo := map[string]interface{}{
+			"email":       email,
+			"meetings":    c.meetings,
+			"lastMeeting": c.lastMeeting.Format("2006-01-02"),
+		}
+		if c.name != "" {
+			contactInfo["name"] = c.name
+		}
+		contactList = append(contactList, contactInfo)
+	}
+	calendarContactFields.Select(contactList)
+
+	result := map[string]interface{}{
+		"count":         len(contactList),
+		"eventsScanned": len(events),
+		"contacts":      contactList,
+	}
+	if pageToken != "" {
+		result["truncated"] = true
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal contacts: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func calendarCreateCalendarHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	summary, _ := arguments["summary"].(string)
+	description, _ := arguments["description"].(string)
+	timeZone, _ := arguments["time_zone"].(string)
+
+	if summary == "" {
+		return mcp.NewToolResultError("summary is required"), nil
+	}
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid time_zone: %v", err)), nil
+		}
+	}
+
+	created, err := calendarService(profile).Calendars.Insert(&calendar.Calendar{
+		Summary:     summary,
+		Description: description,
+		TimeZone:    timeZone,
+	}).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create calendar: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"id":       created.Id,
+		"summary":  created.Summary,
+		"timeZone": created.TimeZone,
+	}
+	if created.Description != "" {
+		result["description"] = created.Description
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal calendar: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func calendarDeleteCalendarHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID, _ := arguments["calendar_id"].(string)
+
+	if calendarID == "" {
+		return mcp.NewToolResultError("calendar_id is required"), nil
+	}
+	if calendarID == "primary" {
+		return mcp.NewToolResultError("the primary calendar cannot be deleted"), nil
+	}
+
+	impact := fmt.Sprintf("Permanently deletes calendar %s and all of its events. This cannot be undone.", calendarID)
+	if confirm := requireConfirmation(arguments, "calendar_delete_calendar:"+profile+":"+calendarID, impact); confirm != nil {
+		return confirm, nil
+	}
+
+	if err := calendarService(profile).Calendars.Delete(calendarID).Do(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete calendar: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted calendar with ID: %s", calendarID)), nil
+}
+
+func calendarAclHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	action, _ := arguments["action"].(string)
+
+	switch action {
+	case "list":
+		return calendarListAclHandler(arguments)
+	case "grant":
+		return calendarGrantAclHandler(arguments)
+	case "revoke":
+		return calendarRevokeAclHandler(arguments)
+	default:
+		return mcp.NewToolResultError("Invalid action. Must be one of: list, grant, revoke"), nil
+	}
+}
+
+// calendarAclFields selects the fields of listed sharing rules for the
+// configured output verbosity.
+var calendarAclFields = util.FieldSet{
+	Terse: []string{"id", "role"},
+}
+
+func calendarListAclHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+
+	rules := make([]map[string]interface{}, 0)
+	pageToken := ""
+	for {
+		listCall := calendarService(profile).Acl.List(calendarID)
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		resp, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list sharing rules: %v", err)), nil
+		}
+
+		for _, rule := range resp.Items {
+			ruleInfo := map[string]interface{}{
+				"id":   rule.Id,
+				"role": rule.Role,
+			}
+			if rule.Scope != nil {
+				ruleInfo["scopeType"] = rule.Scope.Type
+				if rule.Scope.Value != "" {
+					ruleInfo["scopeValue"] = rule.Scope.Value
+				}
+			}
+			rules = append(rules, ruleInfo)
+		}
+
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	calendarAclFields.Select(rules)
+
+	result := map[string]interface{}{
+		"calendarId": calendarID,
+		"count":      len(rules),
+		"rules":      rules,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal sharing rules: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func calendarGrantAclHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	email, _ := arguments["email"].(string)
+	role, _ := arguments["role"].(string)
+
+	if email == "" {
+		return mcp.NewToolResultError("email is required for grant action"), nil
+	}
+	if role == "" {
+		role = "reader"
+	}
+	switch role {
+	case "freeBusyReader", "reader", "writer", "owner":
+	default:
+		return mcp.NewToolResultError("Invalid role. Must be one of: freeBusyReader, reader, writer, owner"), nil
+	}
+
+	rule, err := calendarService(profile).Acl.Insert(calendarID, &calendar.AclRule{
+		Role: role,
+		Scope: &calendar.AclRuleScope{
+			Type:  "user",
+			Value: email,
+		},
+	}).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to grant access: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully granted %s access to %s on calendar %s (rule ID: %s)", role, email, calendarID, rule.Id)), nil
+}
+
+func calendarRevokeAclHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	email, _ := arguments["email"].(string)
+
+	if email == "" {
+		return mcp.NewToolResultError("email is required for revoke action"), nil
+	}
+
+	// User rules are identified as "user:{email}"
+	ruleID := "user:" + email
+	if err := calendarService(profile).Acl.Delete(calendarID, ruleID).Do(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to revoke access: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully revoked access for %s on calendar %s", email, calendarID)), nil
+}
+
+func calendarDefaultRemindersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	action, _ := arguments["action"].(string)
+
+	switch action {
+	case "get":
+		return calendarGetDefaultRemindersHandler(arguments)
+	case "set":
+		return calendarSetDefaultRemindersHandler(arguments)
+	default:
+		return mcp.NewToolResultError("Invalid action. Must be one of: get, set"), nil
+	}
+}
+
+// maxDefaultReminders is the most default reminders the Calendar API accepts
+// per calendar.
+const maxDefaultReminders = 5
+
+// notificationTypes lists the calendar notification types, all delivered by email.
+var notificationTypes = []string{"eventCreation", "eventChange", "eventCancellation", "eventResponse", "agenda"}
+
+func calendarGetDefaultRemindersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+
+	entry, err := calendarService(profile).CalendarList.Get(calendarID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get calendar: %v", err)), nil
+	}
+
+	yamlResult, err := yaml.Marshal(defaultRemindersInfo(entry))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal reminders: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func calendarSetDefaultRemindersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	remindersStr, hasReminders := arguments["reminders"].(string)
+	notificationsStr, hasNotifications := arguments["notifications"].(string)
+	if !hasReminders && !hasNotifications {
+		return mcp.NewToolResultError("reminders or notifications is required for set action"), nil
+	}
+
+	entry, err := calendarService(profile).CalendarList.Get(calendarID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get calendar: %v", err)), nil
+	}
+
+	if hasReminders {
+		reminders, err := parseReminders(remindersStr)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		entry.DefaultReminders = reminders
+		// An empty list must still be sent to remove the existing reminders
+		entry.ForceSendFields = append(entry.ForceSendFields, "DefaultReminders")
+	}
+
+	if hasNotifications {
+		notifications := make([]*calendar.CalendarNotification, 0)
+		for _, notificationType := range strings.Split(notificationsStr, ",") {
+			notificationType = strings.TrimSpace(notificationType)
+			if notificationType == "" {
+				continue
+			}
+			if !slices.Contains(notificationTypes, notificationType) {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid notification type %q. Must be one of: %s", notificationType, strings.Join(notificationTypes, ", "))), nil
+			}
+			notifications = append(notifications, &calendar.CalendarNotification{Method: "email", Type: notificationType})
+		}
+		entry.NotificationSettings = &calendar.CalendarListEntryNotificationSettings{
+			Notifications:   notifications,
+			ForceSendFields: []string{"Notifications"},
+		}
+	}
+
+	updated, err := calendarService(profile).CalendarList.Update(calendarID, entry).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update calendar: %v", err)), nil
+	}
+
+	yamlResult, err := yaml.Marshal(defaultRemindersInfo(updated))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal reminders: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// parseReminders parses comma-separated method:minutes pairs such as
+// "popup:10,email:60".
+func parseReminders(value string) ([]*calendar.EventReminder, error) {
+	reminders := make([]*calendar.EventReminder, 0)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, minutesStr, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid reminder %q: expected method:minutes, e.g. popup:10", pair)
+		}
+		method = strings.TrimSpace(method)
+		if method != "popup" && method != "email" {
+			return nil, fmt.Errorf("invalid reminder method %q. Must be one of: popup, email", method)
+		}
+		minutes, err := strconv.ParseInt(strings.TrimSpace(minutesStr), 10, 64)
+		if err != nil || minutes < 0 || minutes > 40320 {
+			return nil, fmt.Errorf("invalid reminder minutes %q: must be a number from 0 to 40320 (4 weeks)", minutesStr)
 		}
+		reminders = append(reminders, &calendar.EventReminder{Method: method, Minutes: minutes, ForceSendFields: []string{"Minutes"}})
 	}
+	if len(reminders) > maxDefaultReminders {
+		return nil, fmt.Errorf("at most %d default reminders are allowed, got %d", maxDefaultReminders, len(reminders))
+	}
+	return reminders, nil
+}
 
-	// Merge overlapping busy times
-	mergedBusyTimes := mergeTimeSlots(allBusyTimes)
+// defaultRemindersInfo summarizes a calendar's default reminders and
+// notification settings.
+func defaultRemindersInfo(entry *calendar.CalendarListEntry) map[string]interface{} {
+	reminders := make([]map[string]interface{}, 0, len(entry.DefaultReminders))
+	for _, reminder := range entry.DefaultReminders {
+		reminders = append(reminders, map[string]interface{}{
+			"method":  reminder.Method,
+			"minutes": reminder.Minutes,
+		})
+	}
+	notifications := make([]string, 0)
+	if entry.NotificationSettings != nil {
+		for _, notification := range entry.NotificationSettings.Notifications {
+			notifications = append(notifications, notification.Type)
+		}
+	}
+	return map[string]interface{}{
+		"calendarId":       entry.Id,
+		"summary":          entry.Summary,
+		"defaultReminders": reminders,
+		"notifications":    notifications,
+	}
+}
 
-	// Find available slots
-	availableSlots := findAvailableSlots(
-		startDate,
-		endDate,
-		mergedBusyTimes,
-		time.Duration(durationMinutes)*time.Minute,
-		workingHoursStart,
-		workingHoursEnd,
-		int(maxResults),
-	)
+func calendarFindDuplicatesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	deleteDuplicates, _ := arguments["delete_duplicates"].(bool)
+	dryRun, _ := arguments["dry_run"].(bool)
 
-	// Format results
-	result := map[string]interface{}{
-		"available_slots": make([]map[string]string, 0),
-		"duration_minutes": durationMinutes,
-		"busy_times": make([]map[string]string, 0),
+	timeMinStr, _ := arguments["time_min"].(string)
+	if timeMinStr == "" {
+		timeMinStr = time.Now().Format(time.RFC3339)
+	}
+	timeMaxStr, _ := arguments["time_max"].(string)
+	if timeMaxStr == "" {
+		timeMaxStr = time.Now().AddDate(0, 0, 7).Format(time.RFC3339)
 	}
 
-	if guestsStr != "" {
-		result["guests_checked"] = guestsStr
+	timeMin, timeMax, err := util.ParseTimeRange(timeMinStr, timeMaxStr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	if room != "" {
-		result["room_filter"] = room
+	if err := checkQuerySpan(timeMin, timeMax); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Add available slots
-	for _, slot := range availableSlots {
-		slotInfo := map[string]string{
-			"start": slot.Start.Format("2006-01-02 15:04"),
-			"end":   slot.End.Format("2006-01-02 15:04"),
-			"day":   slot.Start.Format("Monday"),
+	events, pageToken, _, err := listEvents(profile, calendarID, timeMin, timeMax, 250, maxPagesLimit, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Group events by title and normalized start/end
+	groups := make(map[string][]*calendar.Event)
+	keys := make([]string, 0)
+	for _, event := range events {
+		key := strings.Join([]string{
+			strings.TrimSpace(event.Summary),
+			normalizedEventTime(event.Start),
+			normalizedEventTime(event.End),
+		}, "|")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
 		}
-		result["available_slots"] = append(result["available_slots"].([]map[string]string), slotInfo)
+		groups[key] = append(groups[key], event)
 	}
 
-	// Add busy time details
-	for _, busy := range busyDetails {
-		busyInfo := map[string]string{
-			"start":     busy.Start.Format("2006-01-02 15:04"),
-			"end":       busy.End.Format("2006-01-02 15:04"),
-			"summary":   busy.Summary,
-			"organizer": busy.Organizer,
+	duplicates := make([]map[string]interface{}, 0)
+	allRemoveIds := make([]string, 0)
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
 		}
-		
-		// Add calendar info to identify whose calendar it is
-		if busy.CalendarId == "primary" {
-			busyInfo["calendar"] = "Your calendar"
-		} else {
-			busyInfo["calendar"] = busy.CalendarId
+
+		// Keep the oldest copy, which is most likely the original
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Created < group[j].Created
+		})
+
+		removeIds := make([]string, 0, len(group)-1)
+		for _, event := range group[1:] {
+			removeIds = append(removeIds, event.Id)
 		}
-		
-		result["busy_times"] = append(result["busy_times"].([]map[string]string), busyInfo)
+		allRemoveIds = append(allRemoveIds, removeIds...)
+
+		duplicates = append(duplicates, map[string]interface{}{
+			"summary":    group[0].Summary,
+			"start":      formatEventTime(group[0].Start),
+			"end":        formatEventTime(group[0].End),
+			"copies":     len(group),
+			"keepId":     group[0].Id,
+			"duplicates": removeIds,
+		})
+	}
+
+	deleted := 0
+	failures := make([]string, 0)
+	if deleteDuplicates && !dryRun && len(allRemoveIds) > 0 {
+		// The token confirms exactly this set of events, so a changed calendar
+		// needs a new confirmation
+		impact := fmt.Sprintf("Permanently deletes %d duplicate events from calendar %s: %s. This cannot be undone.", len(allRemoveIds), calendarID, strings.Join(allRemoveIds, ", "))
+		operation := "calendar_find_duplicates:" + profile + ":" + calendarID + ":" + strings.Join(allRemoveIds, ",")
+		if confirm := requireConfirmation(arguments, operation, impact); confirm != nil {
+			return confirm, nil
+		}
+
+		for _, eventID := range allRemoveIds {
+			if err := calendarService(profile).Events.Delete(calendarID, eventID).Do(); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", eventID, err))
+				continue
+			}
+			deleted++
+		}
+	}
+
+	result := map[string]interface{}{
+		"eventsScanned": len(events),
+		"groups":        len(duplicates),
+		"duplicates":    duplicates,
+		"dry_run":       dryRun,
+	}
+	if deleteDuplicates && !dryRun {
+		result["deleted"] = deleted
+	}
+	if len(failures) > 0 {
+		result["failures"] = failures
+	}
+	if pageToken != "" {
+		result["truncated"] = true
 	}
 
 	yamlResult, err := yaml.Marshal(result)
@@ -426,240 +2813,206 @@ func calendarFindTimeSlotHandler(arguments map[string]interface{}) (*mcp.CallToo
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-type timeSlot struct {
-	Start time.Time
-	End   time.Time
-}
-
-type busyTime struct {
-	Start       time.Time
-	End         time.Time
-	Summary     string
-	Organizer   string
-	CalendarId  string
-}
-
-func mergeTimeSlots(slots []timeSlot) []timeSlot {
-	if len(slots) == 0 {
-		return slots
+// normalizedEventTime returns a comparable representation of an event time:
+// the instant in UTC for timed events, or the date for all-day events.
+func normalizedEventTime(eventTime *calendar.EventDateTime) string {
+	if eventTime == nil {
+		return ""
 	}
-
-	// Sort slots by start time
-	for i := 0; i < len(slots); i++ {
-		for j := i + 1; j < len(slots); j++ {
-			if slots[i].Start.After(slots[j].Start) {
-				slots[i], slots[j] = slots[j], slots[i]
-			}
-		}
+	if eventTime.DateTime == "" {
+		return eventTime.Date
 	}
-
-	merged := []timeSlot{slots[0]}
-	for i := 1; i < len(slots); i++ {
-		last := &merged[len(merged)-1]
-		if slots[i].Start.Before(last.End) || slots[i].Start.Equal(last.End) {
-			// Overlapping or adjacent, merge them
-			if slots[i].End.After(last.End) {
-				last.End = slots[i].End
-			}
-		} else {
-			// No overlap, add as new slot
-			merged = append(merged, slots[i])
-		}
+	t, err := time.Parse(time.RFC3339, eventTime.DateTime)
+	if err != nil {
+		return eventTime.DateTime
 	}
-
-	return merged
+	return t.UTC().Format(time.RFC3339)
 }
 
-func findAvailableSlots(startDate, endDate time.Time, busySlots []timeSlot, duration time.Duration, workStart, workEnd string, maxResults int) []timeSlot {
-	availableSlots := make([]timeSlot, 0)
-	
-	// Parse working hours
-	workStartHour, workStartMin := parseTimeString(workStart)
-	workEndHour, workEndMin := parseTimeString(workEnd)
-
-	currentDate := startDate
-	for currentDate.Before(endDate) && len(availableSlots) < maxResults {
-		// Set working hours for current day
-		dayStart := time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), workStartHour, workStartMin, 0, 0, currentDate.Location())
-		dayEnd := time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), workEndHour, workEndMin, 0, 0, currentDate.Location())
+func calendarGetSettingsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 
-		// Skip weekends
-		if currentDate.Weekday() == time.Saturday || currentDate.Weekday() == time.Sunday {
-			currentDate = currentDate.AddDate(0, 0, 1)
-			continue
+	settings := make(map[string]string)
+	pageToken := ""
+	for {
+		listCall := calendarService(profile).Settings.List()
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
 		}
 
-		// Ensure we don't go before the start date
-		if dayStart.Before(startDate) {
-			dayStart = startDate
-		}
-		// Ensure we don't go after the end date
-		if dayEnd.After(endDate) {
-			dayEnd = endDate
+		resp, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list calendar settings: %v", err)), nil
 		}
 
-		// Find free slots in this day
-		currentTime := dayStart
-		for currentTime.Add(duration).Before(dayEnd) || currentTime.Add(duration).Equal(dayEnd) {
-			slotEnd := currentTime.Add(duration)
-			
-			// Check if this slot conflicts with any busy time
-			isAvailable := true
-			for _, busySlot := range busySlots {
-				if (currentTime.Before(busySlot.End) && slotEnd.After(busySlot.Start)) {
-					// Conflict found
-					isAvailable = false
-					// Move current time to the end of the busy slot
-					if busySlot.End.After(currentTime) {
-						currentTime = busySlot.End
-					}
-					break
-				}
-			}
+		for _, setting := range resp.Items {
+			settings[setting.Id] = setting.Value
+		}
 
-			if isAvailable {
-				availableSlots = append(availableSlots, timeSlot{Start: currentTime, End: slotEnd})
-				if len(availableSlots) >= maxResults {
-					break
-				}
-				// Move to next potential slot (30 minute increments)
-				currentTime = currentTime.Add(30 * time.Minute)
-			}
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
 		}
+	}
 
-		currentDate = currentDate.AddDate(0, 0, 1)
+	result := map[string]interface{}{
+		"timezone":           settings["timezone"],
+		"defaultEventLength": settings["defaultEventLength"],
+		"weekStart":          settings["weekStart"],
+		"locale":             settings["locale"],
+		"format24HourTime":   settings["format24HourTime"],
+		"allSettings":        settings,
 	}
 
-	return availableSlots
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal settings: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-func parseTimeString(timeStr string) (hour, minute int) {
-	parts := strings.Split(timeStr, ":")
-	if len(parts) != 2 {
-		return 9, 0 // Default to 9:00
+// maxPrepAttendees caps how many attendees calendar_meeting_prep searches mail for.
+const maxPrepAttendees = 20
+
+func calendarMeetingPrepHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	calendarID := calendarIDArg(arguments)
+	eventID, _ := arguments["event_id"].(string)
+	if eventID == "" {
+		return mcp.NewToolResultError("event_id is required"), nil
 	}
-	
-	if _, err := fmt.Sscanf(parts[0], "%d", &hour); err != nil {
-		hour = 9 // Default hour
+	includeInternal, _ := arguments["include_internal"].(bool)
+
+	days := 30
+	if value, ok := arguments["days"].(float64); ok && value > 0 {
+		days = int(value)
 	}
-	if _, err := fmt.Sscanf(parts[1], "%d", &minute); err != nil {
-		minute = 0 // Default minute
+	perAttendee := 3
+	if value, ok := arguments["emails_per_attendee"].(float64); ok && value > 0 {
+		perAttendee = int(value)
 	}
-	return hour, minute
-}
-
-func calendarGetBusyTimesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	usersStr, _ := arguments["users"].(string)
-	startDateStr, _ := arguments["start_date"].(string)
-	endDateStr, _ := arguments["end_date"].(string)
 
-	startDate, err := time.Parse(time.RFC3339, startDateStr)
+	event, err := calendarService(profile).Events.Get(calendarID, eventID).Do()
 	if err != nil {
-		return mcp.NewToolResultError("Invalid start_date format"), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get event: %v", err)), nil
 	}
-	endDate, err := time.Parse(time.RFC3339, endDateStr)
+
+	self, err := authenticatedEmail(profile)
 	if err != nil {
-		return mcp.NewToolResultError("Invalid end_date format"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	selfDomain := senderDomain(self)
 
-	// Determine calendars to check
-	calendarsToCheck := []string{"primary"}
-	if usersStr != "" {
-		calendarsToCheck = []string{}
-		for _, user := range strings.Split(usersStr, ",") {
-			calendarsToCheck = append(calendarsToCheck, strings.TrimSpace(user))
+	emails := make([]string, 0, len(event.Attendees))
+	skipped := 0
+	for _, attendee := range event.Attendees {
+		if attendee.Self || attendee.Resource || strings.EqualFold(attendee.Email, self) {
+			continue
 		}
-	}
-
-	// Collect busy times from all calendars
-	busyDetails := make([]busyTime, 0)
-	
-	for _, calendarId := range calendarsToCheck {
-		events, err := calendarService().Events.List(calendarId).
-			ShowDeleted(false).
-			SingleEvents(true).
-			TimeMin(startDate.Format(time.RFC3339)).
-			TimeMax(endDate.Format(time.RFC3339)).
-			OrderBy("startTime").
-			Do()
-		
-		if err != nil {
-			// Skip calendars we can't access but include error info
-			busyDetails = append(busyDetails, busyTime{
-				Summary:    fmt.Sprintf("Error accessing calendar: %s", err.Error()),
-				CalendarId: calendarId,
-			})
+		if !includeInternal && strings.EqualFold(senderDomain(attendee.Email), selfDomain) {
+			continue
+		}
+		if len(emails) >= maxPrepAttendees {
+			skipped++
 			continue
 		}
+		emails = append(emails, attendee.Email)
+	}
 
-		for _, event := range events.Items {
-			if event.Start.DateTime != "" && event.End.DateTime != "" {
-				start, _ := time.Parse(time.RFC3339, event.Start.DateTime)
-				end, _ := time.Parse(time.RFC3339, event.End.DateTime)
-				
-				// Get organizer info
-				organizer := ""
-				if event.Organizer != nil {
-					if event.Organizer.DisplayName != "" {
-						organizer = event.Organizer.DisplayName
-					} else {
-						organizer = event.Organizer.Email
-					}
-				}
-				
-				busyDetails = append(busyDetails, busyTime{
-					Start:      start,
-					End:        end,
-					Summary:    event.Summary,
-					Organizer:  organizer,
-					CalendarId: calendarId,
-				})
-			}
+	recent, errs := util.MapConcurrent(emails, 5, func(email string) ([]map[string]interface{}, error) {
+		return recentEmailsFrom(profile, email, days, perAttendee)
+	})
+
+	attendees := make([]map[string]interface{}, 0, len(emails))
+	for i, email := range emails {
+		attendeeInfo := map[string]interface{}{
+			"email": email,
+		}
+		if errs[i] != nil {
+			attendeeInfo["error"] = errs[i].Error()
+		} else {
+			attendeeInfo["recentEmailCount"] = len(recent[i])
+			attendeeInfo["recentEmails"] = recent[i]
 		}
+		attendees = append(attendees, attendeeInfo)
 	}
 
-	// Sort busy times by start time
-	for i := 0; i < len(busyDetails); i++ {
-		for j := i + 1; j < len(busyDetails); j++ {
-			if busyDetails[i].Start.After(busyDetails[j].Start) {
-				busyDetails[i], busyDetails[j] = busyDetails[j], busyDetails[i]
-			}
+	eventInfo := map[string]interface{}{
+		"id":      event.Id,
+		"summary": event.Summary,
+		"start":   formatEventTime(event.Start),
+		"end":     formatEventTime(event.End),
+	}
+	if event.Description != "" {
+		eventInfo["description"] = event.Description
+	}
+	if event.Location != "" {
+		eventInfo["location"] = event.Location
+	}
+	if event.HangoutLink != "" {
+		eventInfo["meetLink"] = event.HangoutLink
+	}
+	if event.Organizer != nil {
+		eventInfo["organizer"] = event.Organizer.Email
+	}
+	allAttendees := make([]string, 0, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		if attendee.Optional {
+			allAttendees = append(allAttendees, fmt.Sprintf("%s (%s, optional)", attendee.Email, attendee.ResponseStatus))
+		} else {
+			allAttendees = append(allAttendees, fmt.Sprintf("%s (%s)", attendee.Email, attendee.ResponseStatus))
 		}
 	}
+	eventInfo["attendees"] = allAttendees
+	eventInfo["responses"] = responseCounts(event.Attendees)
 
-	// Format results
 	result := map[string]interface{}{
-		"period": map[string]string{
-			"start": startDate.Format("2006-01-02 15:04"),
-			"end":   endDate.Format("2006-01-02 15:04"),
-		},
-		"calendars_checked": calendarsToCheck,
-		"busy_times":        make([]map[string]interface{}, 0),
-		"total_busy_times":  len(busyDetails),
+		"event":          eventInfo,
+		"searchedDays":   days,
+		"attendeeEmails": attendees,
+	}
+	if skipped > 0 {
+		result["skippedAttendees"] = skipped
 	}
 
-	// Add busy time details
-	for _, busy := range busyDetails {
-		busyInfo := map[string]interface{}{
-			"start":     busy.Start.Format("2006-01-02 15:04"),
-			"end":       busy.End.Format("2006-01-02 15:04"),
-			"calendar":  busy.CalendarId,
-			"summary":   busy.Summary,
-			"organizer": busy.Organizer,
-			"day":       busy.Start.Format("Monday"),
-		}
+	yamlResult, err := yaml.Marshal(util.SanitizeValue(result))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal meeting prep: %v", err)), nil
+	}
 
-		// Calculate duration
-		duration := busy.End.Sub(busy.Start)
-		busyInfo["duration_minutes"] = int(duration.Minutes())
-		
-		result["busy_times"] = append(result["busy_times"].([]map[string]interface{}), busyInfo)
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func calendarListColorsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+
+	colors, err := calendarService(profile).Colors.Get().Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get colors: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"event":    colorPalette(colors.Event),
+		"calendar": colorPalette(colors.Calendar),
 	}
 
 	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal colors: %v", err)), nil
 	}
 
 	return mcp.NewToolResultText(string(yamlResult)), nil
-}
\ No newline at end of file
+}
+
+// colorPalette converts a color ID to definition map into output form.
+func colorPalette(definitions map[string]calendar.ColorDefinition) map[string]map[string]string {
+	palette := make(map[string]map[string]string, len(definitions))
+	for id, definition := range definitions {
+		palette[id] = map[string]string{
+			"background": definition.Background,
+			"foreground": definition.Foreground,
+		}
+	}
+	return palette
+}
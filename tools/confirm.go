@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// confirmationTTL is how long a confirmation token for a destructive operation
+// stays valid.
+const confirmationTTL = 5 * time.Minute
+
+var (
+	confirmationsMu sync.Mutex
+	confirmations   = make(map[string]pendingConfirmation)
+)
+
+type pendingConfirmation struct {
+	operation string
+	expires   time.Time
+}
+
+// withConfirmation adds the optional "confirmation_token" argument used by
+// destructive tools to confirm an operation previewed by an earlier call.
+func withConfirmation() mcp.ToolOption {
+	return mcp.WithString("confirmation_token", mcp.Description("Token returned by a previous call to confirm this destructive operation. Call once without it to see the impact and get a token"))
+}
+
+// requireConfirmation implements the two-step confirm flow for destructive
+// operations. operation identifies the exact action (tool, profile and target),
+// so a token only confirms what it was issued for. Without a token it issues
+// one and returns a result describing impact; with a valid token it consumes it
+// and returns nil, meaning the caller may proceed.
+func requireConfirmation(arguments map[string]interface{}, operation string, impact string) *mcp.CallToolResult {
+	token, _ := arguments["confirmation_token"].(string)
+
+	confirmationsMu.Lock()
+	defer confirmationsMu.Unlock()
+
+	now := time.Now()
+	for key, pending := range confirmations {
+		if now.After(pending.expires) {
+			delete(confirmations, key)
+		}
+	}
+
+	if token != "" {
+		pending, ok := confirmations[token]
+		if !ok || pending.operation != operation {
+			return mcp.NewToolResultError("invalid or expired confirmation_token. Call again without it to get a new token")
+		}
+		delete(confirmations, token)
+		return nil
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to generate confirmation token: %v", err))
+	}
+	token = hex.EncodeToString(random)
+	confirmations[token] = pendingConfirmation{
+		operation: operation,
+		expires:   now.Add(confirmationTTL),
+	}
+
+	result := map[string]interface{}{
+		"confirmationRequired": true,
+		"impact":               impact,
+		"confirmationToken":    token,
+		"expiresIn":            confirmationTTL.String(),
+		"hint":                 "Call again with the same arguments and confirmation_token to proceed.",
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+
+	return mcp.NewToolResultText(string(yamlResult))
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/nguyenvanduocit/google-mcp/services"
+	"google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"gopkg.in/yaml.v3"
+)
+
+var directoryServices = services.NewProfileCache(func(client *http.Client) (*admin.Service, error) {
+	return admin.NewService(context.Background(), option.WithHTTPClient(client))
+})
+
+// directoryService returns the Admin SDK Directory service for the given credential profile ("" selects the default account).
+func directoryService(profile string) *admin.Service {
+	srv, err := directoryServices.Get(profile)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create Directory service: %v", err))
+	}
+	return srv
+}
+
+func gChatDirectoryUserHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	user, _ := arguments["user"].(string)
+	adminView := true
+	if value, ok := arguments["admin_view"].(bool); ok {
+		adminView = value
+	}
+
+	// Chat user IDs are the Directory user IDs behind a "users/" prefix
+	userKey := strings.TrimPrefix(strings.TrimSpace(user), "users/")
+	if userKey == "" {
+		return mcp.NewToolResultError("user is required"), nil
+	}
+
+	viewType := "admin_view"
+	if !adminView {
+		viewType = "domain_public"
+	}
+
+	directoryUser, err := directoryService(profile).Users.Get(userKey).ViewType(viewType).Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if adminView && errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get directory user: %v\nThe admin view requires a Workspace admin account; retry with admin_view set to false for the fields visible to everyone in the domain", err)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get directory user: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"id":           directoryUser.Id,
+		"chatUserName": "users/" + directoryUser.Id,
+		"primaryEmail": directoryUser.PrimaryEmail,
+	}
+	if directoryUser.Name != nil {
+		result["fullName"] = directoryUser.Name.FullName
+	}
+	if directoryUser.OrgUnitPath != "" {
+		result["orgUnitPath"] = directoryUser.OrgUnitPath
+	}
+	if adminView {
+		result["suspended"] = directoryUser.Suspended
+		result["isAdmin"] = directoryUser.IsAdmin
+	}
+
+	if organization := primaryOrganization(directoryUser); organization != nil {
+		if organization.Title != "" {
+			result["title"] = organization.Title
+		}
+		if organization.Department != "" {
+			result["department"] = organization.Department
+		}
+		if organization.Name != "" {
+			result["organization"] = organization.Name
+		}
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal user: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// primaryOrganization returns the user's primary organization entry, or the
+// first one when none is marked primary. The API types organizations loosely,
+// so they are decoded here.
+func primaryOrganization(user *admin.User) *admin.UserOrganization {
+	if user.Organizations == nil {
+		return nil
+	}
+	data, err := json.Marshal(user.Organizations)
+	if err != nil {
+		return nil
+	}
+	var organizations []*admin.UserOrganization
+	if err := json.Unmarshal(data, &organizations); err != nil || len(organizations) == 0 {
+		return nil
+	}
+	for _, organization := range organizations {
+		if organization.Primary {
+			return organization
+		}
+	}
+	return organizations[0]
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nguyenvanduocit/google-mcp/services"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+var driveServices = services.NewProfileCache(func(client *http.Client) (*drive.Service, error) {
+	return drive.NewService(context.Background(), option.WithHTTPClient(client))
+})
+
+// driveService returns the Drive service for the given credential profile ("" selects the default account).
+func driveService(profile string) *drive.Service {
+	srv, err := driveServices.Get(profile)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create Drive service: %v", err))
+	}
+	return srv
+}
+
+// createGoogleDoc creates an empty Google Doc with the given title and returns
+// its Drive file, including the link to open it.
+func createGoogleDoc(profile string, title string) (*drive.File, error) {
+	file, err := driveService(profile).Files.Create(&drive.File{
+		Name:     title,
+		MimeType: "application/vnd.google-apps.document",
+	}).Fields("id", "name", "mimeType", "webViewLink").Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document: %v", err)
+	}
+	return file, nil
+}
+
+// deleteDriveFile deletes a Drive file, such as a document created for an
+// operation that then failed.
+func deleteDriveFile(profile string, fileID string) error {
+	if err := driveService(profile).Files.Delete(fileID).Do(); err != nil {
+		return fmt.Errorf("failed to delete Drive file %s: %v", fileID, err)
+	}
+	return nil
+}
+
+// getDriveFile returns the metadata of a Drive file needed to reference it
+// elsewhere, such as in a calendar event attachment.
+func getDriveFile(profile string, fileID string) (*drive.File, error) {
+	file, err := driveService(profile).Files.Get(fileID).Fields("id", "name", "mimeType", "webViewLink", "iconLink").Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Drive file %s: %v", fileID, err)
+	}
+	return file, nil
+}
@@ -1,14 +1,24 @@
 package tools
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/nguyenvanduocit/google-mcp/services"
 	"github.com/nguyenvanduocit/google-mcp/util"
 	"google.golang.org/api/chat/v1"
+	"google.golang.org/api/googleapi"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +26,16 @@ func RegisterGChatTool(s *server.MCPServer) {
 	// List spaces tool
 	listSpacesTool := mcp.NewTool("gchat_list_spaces",
 		mcp.WithDescription("List all available Google Chat spaces/rooms"),
+		mcp.WithString("space_type", mcp.Description("Only return spaces of this type: SPACE (or ROOM), GROUP_CHAT (or GROUP_DM), or DIRECT_MESSAGE")),
+		mcp.WithBoolean("resolve_dm_members", mcp.Description("For direct messages, include the other participant (default: false). Chat identifies most users by numeric ID, so their email is only included when ENABLE_DIRECTORY_LOOKUP=true")),
+		withProfile(),
+	)
+
+	// Find space tool
+	findSpaceTool := mcp.NewTool("gchat_find_space",
+		mcp.WithDescription("Find Google Chat spaces by display name (case-insensitive, partial match) and return their resource names"),
+		mcp.WithString("display_name", mcp.Required(), mcp.Description("Full or partial display name of the space (e.g. 'engineering')")),
+		withProfile(),
 	)
 
 	// Send message tool
@@ -24,12 +44,29 @@ func RegisterGChatTool(s *server.MCPServer) {
 		mcp.WithString("space_name", mcp.Required(), mcp.Description("Name of the space to send the message to (e.g. spaces/1234567890)")),
 		mcp.WithString("message", mcp.Required(), mcp.Description("Text message to send")),
 		mcp.WithString("thread_name", mcp.Description("Optional thread name to reply to (e.g. spaces/1234567890/threads/abcdef)")),
-		mcp.WithBoolean("use_markdown", mcp.Description("Whether to format the message using markdown (default: false)")),
+		mcp.WithBoolean("use_markdown", mcp.Description("Whether to format the message using markdown (default: false; same as format=markdown)")),
+		withChatFormat(),
+		mcp.WithString("reply_option", mcp.Description("When thread_name is set: REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD (default) starts a new thread if the thread is gone, REPLY_MESSAGE_OR_FAIL fails instead")),
+		mcp.WithString("private_to_user", mcp.Description("Optional user (email or users/{id}) who is the only one to see the message. Private messages require Chat app authentication")),
+		mcp.WithString("accessory_widgets", mcp.Description(`JSON array of accessory widgets shown as buttons below the message, e.g. [{"buttonList":{"buttons":[{"text":"Open","onClick":{"openLink":{"url":"https://example.com"}}}]}}]. Requires Chat app authentication`)),
+		mcp.WithString("mentions", mcp.Description("Comma-separated emails or users/{id} to @mention so they are notified. An existing '@email' or '@name' in the message becomes the mention; otherwise mentions are added at the start")),
+		withProfile(),
+	)
+
+	// Broadcast message tool
+	broadcastTool := mcp.NewTool("gchat_broadcast",
+		mcp.WithDescription("Send the same message to multiple Google Chat spaces, reporting success or failure per space"),
+		mcp.WithString("space_names", mcp.Required(), mcp.Description("Comma-separated list of space names (e.g. spaces/123,spaces/456)")),
+		mcp.WithString("message", mcp.Required(), mcp.Description("Text message to send")),
+		mcp.WithBoolean("use_markdown", mcp.Description("Whether to format the message using markdown (default: false; same as format=markdown)")),
+		withChatFormat(),
+		withProfile(),
 	)
 
 	// List users tool (simplified)
 	listUsersTool := mcp.NewTool("gchat_list_users",
 		mcp.WithDescription("List all Google Chat users from all spaces in the organization"),
+		withProfile(),
 	)
 
 	// List messages tool (renamed from Get messages tool)
@@ -38,6 +75,13 @@ func RegisterGChatTool(s *server.MCPServer) {
 		mcp.WithString("space_name", mcp.Required(), mcp.Description("Name of the space to get messages from (e.g. spaces/1234567890)")),
 		mcp.WithNumber("page_size", mcp.Description("Maximum number of messages to return (default: 100)")),
 		mcp.WithString("page_token", mcp.Description("Page token for pagination")),
+		mcp.WithBoolean("include_reactions", mcp.Description("Include emoji reactions and their counts for each message (default: false)")),
+		mcp.WithString("since", mcp.Description("Only return messages created after this time, in RFC3339 format (e.g. for polling new activity)")),
+		mcp.WithString("until", mcp.Description("Only return messages created before this time, in RFC3339 format; combine with since for a date range")),
+		mcp.WithString("thread_name", mcp.Description("Only return messages in this thread of the space (e.g. spaces/1234567890/threads/abcdef)")),
+		mcp.WithBoolean("include_sender_names", mcp.Description("Resolve each sender to a readable senderName using the space's member list (default: false)")),
+		withAutoPaginate(),
+		withProfile(),
 	)
 
 	// Create chat thread tool
@@ -47,23 +91,48 @@ func RegisterGChatTool(s *server.MCPServer) {
 		mcp.WithString("user_emails", mcp.Required(), mcp.Description("Comma-separated list of user email addresses to add to the chat (e.g. user1@example.com,user2@example.com)")),
 		mcp.WithString("initial_message", mcp.Description("Optional initial message to send to the new chat space")),
 		mcp.WithBoolean("external_user_allowed", mcp.Description("Whether to allow users outside the domain (default: false)")),
+		mcp.WithString("space_type", mcp.Description("Kind of space to create: collaboration (everyone can post) or announcement (only space managers can post; the creator is a manager). Default: collaboration")),
+		mcp.WithString("predefined_permission_settings", mcp.Description("Chat's predefined permission settings, as offered in the Chat UI: COLLABORATION_SPACE or ANNOUNCEMENT_SPACE (alternative to space_type)")),
+		withProfile(),
 	)
 
 	// Archive chat thread tool
 	archiveChatThreadTool := mcp.NewTool("gchat_archive_thread",
 		mcp.WithDescription("Archive a Google Chat space to make it read-only"),
 		mcp.WithString("space_name", mcp.Required(), mcp.Description("Name of the space to archive (e.g. spaces/1234567890)")),
+		withProfile(),
 	)
 
 	// Delete chat thread tool
 	deleteChatThreadTool := mcp.NewTool("gchat_delete_thread",
 		mcp.WithDescription("Delete a Google Chat space permanently"),
 		mcp.WithString("space_name", mcp.Required(), mcp.Description("Name of the space to delete (e.g. spaces/1234567890)")),
+		withConfirmation(),
+		withProfile(),
+	)
+
+	// Delete message tool
+	deleteMessageTool := mcp.NewTool("gchat_delete_message",
+		mcp.WithDescription("Delete a Google Chat message. The Chat API cannot delete threads, so check_thread reports whether the message's thread is left empty"),
+		mcp.WithString("message_name", mcp.Required(), mcp.Description("Name of the message to delete (e.g. spaces/1234567890/messages/abcdef)")),
+		mcp.WithBoolean("check_thread", mcp.Description("After deleting, re-query the thread and report whether any messages remain (default: false)")),
+		withConfirmation(),
+		withProfile(),
+	)
+
+	// Download attachment tool
+	downloadAttachmentTool := mcp.NewTool("gchat_download_attachment",
+		mcp.WithDescription("Download a Google Chat message attachment to a local file. Interrupted downloads are resumed, and the file only appears once complete"),
+		mcp.WithString("attachment_name", mcp.Required(), mcp.Description("Name of the attachment from a message listing (e.g. spaces/1234567890/messages/abcdef/attachments/xyz)")),
+		mcp.WithString("output_path", mcp.Required(), mcp.Description("Local file path to save the attachment to. Must not already exist unless overwrite is true")),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace output_path if it already exists (default: false)")),
+		withProfile(),
 	)
 
 	// List all organization users tool (simplified)
 	listAllUsersTool := mcp.NewTool("gchat_list_all_users",
 		mcp.WithDescription("List all unique users and their email addresses across all Google Chat spaces"),
+		withProfile(),
 	)
 
 	// Get thread messages tool
@@ -73,41 +142,236 @@ func RegisterGChatTool(s *server.MCPServer) {
 		mcp.WithString("thread_name", mcp.Required(), mcp.Description("Name of the thread to get messages from (e.g. spaces/1234567890/threads/abcdef)")),
 		mcp.WithNumber("page_size", mcp.Description("Maximum number of messages to return (default: 100)")),
 		mcp.WithString("page_token", mcp.Description("Page token for pagination")),
+		mcp.WithBoolean("include_sender_names", mcp.Description("Resolve each sender to a readable senderName using the space's member list (default: false)")),
+		withProfile(),
 	)
 
 	// Get user info tool
 	getUserInfoTool := mcp.NewTool("gchat_get_user_info",
 		mcp.WithDescription("Get username and display name for a Google Chat user by user ID"),
 		mcp.WithString("user_id", mcp.Required(), mcp.Description("Google Chat user ID in format 'users/123456789'")),
+		withProfile(),
+	)
+
+	// Bulk user resolution tool
+	resolveUsersTool := mcp.NewTool("gchat_resolve_users",
+		mcp.WithDescription("Resolve many Google Chat user IDs to display names (and emails when Chat exposes them) with a single scan of all spaces' members"),
+		mcp.WithString("user_ids", mcp.Required(), mcp.Description("Comma-separated user IDs, e.g. 'users/123,users/456' (the 'users/' prefix is optional)")),
+		withProfile(),
+	)
+
+	// Space membership summary tool
+	spaceMembershipTool := mcp.NewTool("gchat_get_space_membership",
+		mcp.WithDescription("Quickly get a Chat space's member count and the authenticated user's role (MEMBER/MANAGER) without listing all members"),
+		mcp.WithString("space_name", mcp.Required(), mcp.Description("Name of the space (e.g. spaces/1234567890)")),
+		withProfile(),
 	)
 
-	s.AddTool(listSpacesTool, util.ErrorGuard(gChatListSpacesHandler))
-	s.AddTool(sendMessageTool, util.ErrorGuard(gChatSendMessageHandler))
-	s.AddTool(listUsersTool, util.ErrorGuard(gChatListUsersHandler))
-	s.AddTool(listMessagesTool, util.ErrorGuard(gChatListMessagesHandler))
-	s.AddTool(getThreadMessagesTool, util.ErrorGuard(gChatGetThreadMessagesHandler))
-	s.AddTool(createChatThreadTool, util.ErrorGuard(gChatCreateThreadHandler))
-	s.AddTool(archiveChatThreadTool, util.ErrorGuard(gChatArchiveThreadHandler))
-	s.AddTool(deleteChatThreadTool, util.ErrorGuard(gChatDeleteThreadHandler))
-	s.AddTool(listAllUsersTool, util.ErrorGuard(gChatListAllUsersHandler))
-	s.AddTool(getUserInfoTool, util.ErrorGuard(gChatGetUserInfoHandler))
+	s.AddTool(listSpacesTool, util.ErrorGuardNamed(listSpacesTool.Name, gChatListSpacesHandler))
+	s.AddTool(findSpaceTool, util.ErrorGuardNamed(findSpaceTool.Name, gChatFindSpaceHandler))
+	s.AddTool(sendMessageTool, util.ErrorGuardNamed(sendMessageTool.Name, gChatSendMessageHandler))
+	s.AddTool(broadcastTool, util.ErrorGuardNamed(broadcastTool.Name, gChatBroadcastHandler))
+	s.AddTool(listUsersTool, util.ErrorGuardNamed(listUsersTool.Name, gChatListUsersHandler))
+	s.AddTool(listMessagesTool, util.ErrorGuardNamed(listMessagesTool.Name, gChatListMessagesHandler))
+	s.AddTool(getThreadMessagesTool, util.ErrorGuardNamed(getThreadMessagesTool.Name, gChatGetThreadMessagesHandler))
+	s.AddTool(createChatThreadTool, util.ErrorGuardNamed(createChatThreadTool.Name, gChatCreateThreadHandler))
+	s.AddTool(archiveChatThreadTool, util.ErrorGuardNamed(archiveChatThreadTool.Name, gChatArchiveThreadHandler))
+	s.AddTool(deleteChatThreadTool, util.ErrorGuardNamed(deleteChatThreadTool.Name, gChatDeleteThreadHandler))
+	s.AddTool(deleteMessageTool, util.ErrorGuardNamed(deleteMessageTool.Name, gChatDeleteMessageHandler))
+	s.AddTool(downloadAttachmentTool, util.ErrorGuardNamed(downloadAttachmentTool.Name, gChatDownloadAttachmentHandler))
+	s.AddTool(listAllUsersTool, util.ErrorGuardNamed(listAllUsersTool.Name, gChatListAllUsersHandler))
+	s.AddTool(getUserInfoTool, util.ErrorGuardNamed(getUserInfoTool.Name, gChatGetUserInfoHandler))
+	s.AddTool(resolveUsersTool, util.ErrorGuardNamed(resolveUsersTool.Name, gChatResolveUsersHandler))
+	s.AddTool(spaceMembershipTool, util.ErrorGuardNamed(spaceMembershipTool.Name, gChatGetSpaceMembershipHandler))
+
+	// Directory lookups need the Admin SDK scope, which is only requested when enabled
+	if services.DirectoryLookupEnabled() {
+		directoryUserTool := mcp.NewTool("gchat_directory_user",
+			mcp.WithDescription("Look up a user's full profile (name, primary email, org unit, title, department) in the Workspace Admin Directory by Chat user ID or email"),
+			mcp.WithString("user", mcp.Required(), mcp.Description("Chat user ID (users/123456789), Directory user ID, or email address")),
+			mcp.WithBoolean("admin_view", mcp.Description("Use the admin view, which requires a Workspace admin account and includes org unit and account status; set to false for the fields visible to everyone in the domain (default: true)")),
+			withProfile(),
+		)
+		s.AddTool(directoryUserTool, util.ErrorGuardNamed(directoryUserTool.Name, gChatDirectoryUserHandler))
+	}
+}
+
+// chatSpaceFields selects the fields of listed spaces for the configured
+// output verbosity.
+var chatSpaceFields = util.FieldSet{
+	Terse:   []string{"name", "displayName"},
+	Verbose: []string{"createTime", "spaceUri"},
+}
+
+// chatMessageFields selects the fields of listed messages for the configured
+// output verbosity.
+var chatMessageFields = util.FieldSet{
+	Terse:   []string{"name", "text"},
+	Verbose: []string{"lastUpdateTime", "threadReply"},
+}
+
+// chatUserFields selects the fields of listed users for the configured output
+// verbosity.
+var chatUserFields = util.FieldSet{
+	Terse:   []string{"name", "displayName", "email"},
+	Verbose: []string{"type", "role"},
 }
 
 func gChatListSpacesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	spaces, err := services.DefaultGChatService().Spaces.List().Do()
+	profile := profileArg(arguments)
+	spaceType, _ := arguments["space_type"].(string)
+	resolveDMMembers, _ := arguments["resolve_dm_members"].(bool)
+
+	// Accept the legacy type names used by older Chat API versions
+	switch spaceType {
+	case "ROOM":
+		spaceType = "SPACE"
+	case "GROUP_DM":
+		spaceType = "GROUP_CHAT"
+	}
+
+	listCall := gchatService(profile).Spaces.List()
+	switch spaceType {
+	case "":
+	case "SPACE", "GROUP_CHAT", "DIRECT_MESSAGE":
+		listCall = listCall.Filter(fmt.Sprintf(`spaceType = "%s"`, spaceType))
+	default:
+		return mcp.NewToolResultError("Invalid space_type. Must be one of: SPACE, GROUP_CHAT, DIRECT_MESSAGE"), nil
+	}
+
+	spaces, err := listCall.Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list spaces: %v", err)), nil
 	}
 
+	myUserName := ""
 	result := make([]map[string]interface{}, 0)
 	for _, space := range spaces.Spaces {
 		spaceInfo := map[string]interface{}{
 			"name":        space.Name,
 			"displayName": space.DisplayName,
 			"type":        space.Type,
+			"spaceType":   space.SpaceType,
+			"createTime":  space.CreateTime,
+			"spaceUri":    space.SpaceUri,
+		}
+		if space.MembershipCount != nil {
+			spaceInfo["memberCount"] = space.MembershipCount.JoinedDirectHumanUserCount + space.MembershipCount.JoinedGroupCount
 		}
+
+		if resolveDMMembers && space.SpaceType == "DIRECT_MESSAGE" {
+			if myUserName == "" {
+				myUserName = myChatUserName(profile, space.Name)
+			}
+			if other := otherDMParticipant(profile, space.Name, myUserName); other != nil {
+				spaceInfo["with"] = other
+			}
+		}
+
 		result = append(result, spaceInfo)
 	}
+	chatSpaceFields.Select(result)
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal spaces: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// myChatUserName returns the authenticated user's Chat resource name (users/{id})
+// by looking up their membership in a space they belong to.
+func myChatUserName(profile, spaceName string) string {
+	email, err := authenticatedEmail(profile)
+	if err != nil {
+		return ""
+	}
+	membership, err := gchatService(profile).Spaces.Members.Get(fmt.Sprintf("%s/members/%s", spaceName, email)).Do()
+	if err != nil || membership.Member == nil {
+		return ""
+	}
+	return membership.Member.Name
+}
+
+// otherDMParticipant returns the member of a direct message space that is not
+// the authenticated user, or nil when the authenticated user's name is unknown
+// and the two members cannot be told apart. Chat names most users by numeric
+// ID, so the email is looked up in the Workspace directory when directory
+// lookups are enabled.
+func otherDMParticipant(profile, spaceName, myUserName string) map[string]interface{} {
+	if myUserName == "" {
+		return nil
+	}
+
+	members, err := gchatService(profile).Spaces.Members.List(spaceName).Do()
+	if err != nil {
+		return nil
+	}
+
+	for _, member := range members.Memberships {
+		if member.Member == nil || member.Member.Name == myUserName {
+			continue
+		}
+		participant := map[string]interface{}{
+			"name":        member.Member.Name,
+			"displayName": member.Member.DisplayName,
+			"type":        member.Member.Type,
+		}
+		if userPart := strings.TrimPrefix(member.Member.Name, "users/"); strings.Contains(userPart, "@") {
+			participant["email"] = userPart
+		} else if services.DirectoryLookupEnabled() && member.Member.Type == "HUMAN" {
+			if user, err := directoryService(profile).Users.Get(userPart).ViewType("domain_public").Do(); err == nil && user.PrimaryEmail != "" {
+				participant["email"] = user.PrimaryEmail
+			}
+		}
+		return participant
+	}
+
+	return nil
+}
+
+func gChatFindSpaceHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	displayName, _ := arguments["display_name"].(string)
+	query := strings.ToLower(strings.TrimSpace(displayName))
+	if query == "" {
+		return mcp.NewToolResultError("display_name is required"), nil
+	}
+
+	matches := make([]map[string]interface{}, 0)
+	pageToken := ""
+	for {
+		listCall := gchatService(profile).Spaces.List().PageSize(1000)
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		spaces, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list spaces: %v", err)), nil
+		}
+
+		for _, space := range spaces.Spaces {
+			if strings.Contains(strings.ToLower(space.DisplayName), query) {
+				matches = append(matches, map[string]interface{}{
+					"name":        space.Name,
+					"displayName": space.DisplayName,
+					"type":        space.Type,
+				})
+			}
+		}
+
+		if spaces.NextPageToken == "" {
+			break
+		}
+		pageToken = spaces.NextPageToken
+	}
+
+	result := map[string]interface{}{
+		"count":  len(matches),
+		"spaces": matches,
+	}
 
 	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
@@ -118,22 +382,50 @@ func gChatListSpacesHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 }
 
 func gChatSendMessageHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	spaceName := arguments["space_name"].(string)
 	message := arguments["message"].(string)
-	useMarkdown, _ := arguments["use_markdown"].(bool)
+	useMarkdown, err := chatFormatArg(arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	threadName, hasThread := arguments["thread_name"].(string)
 
-	msg := &chat.Message{
-		Text: message,
+	privateToUser, _ := arguments["private_to_user"].(string)
+	replyOption, _ := arguments["reply_option"].(string)
+	if replyOption == "" {
+		replyOption = "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD"
+	}
+	if replyOption != "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD" && replyOption != "REPLY_MESSAGE_OR_FAIL" {
+		return mcp.NewToolResultError("Invalid reply_option. Must be one of: REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD, REPLY_MESSAGE_OR_FAIL"), nil
 	}
 
-	if useMarkdown {
-		msg.FormattedText = message
+	if mentions, _ := arguments["mentions"].(string); mentions != "" {
+		message = applyMentions(message, mentions)
 	}
 
-	createCall := services.DefaultGChatService().Spaces.Messages.Create(spaceName, msg)
+	msg := newChatMessage(message, useMarkdown)
+	if widgetsJSON, _ := arguments["accessory_widgets"].(string); widgetsJSON != "" {
+		widgets, err := parseAccessoryWidgets(widgetsJSON)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid accessory_widgets: %v", err)), nil
+		}
+		msg.AccessoryWidgets = widgets
+	}
+	if privateToUser != "" {
+		viewer := privateToUser
+		if !strings.HasPrefix(viewer, "users/") {
+			viewer = "users/" + viewer
+		}
+		msg.PrivateMessageViewer = &chat.User{Name: viewer}
+	}
+
+	createCall := gchatService(profile).Spaces.Messages.Create(spaceName, msg)
 	if hasThread && threadName != "" {
-		createCall = createCall.ThreadKey(threadName)
+		// thread_name is a thread resource name, so target it through the
+		// message's thread rather than a client-assigned thread key
+		msg.Thread = &chat.Thread{Name: threadName}
+		createCall = createCall.MessageReplyOption(replyOption)
 	}
 
 	resp, err := createCall.Do()
@@ -141,12 +433,216 @@ func gChatSendMessageHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 		return mcp.NewToolResultError(fmt.Sprintf("failed to send message: %v", err)), nil
 	}
 
+	if privateToUser != "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Private message sent successfully to %s. Message ID: %s", privateToUser, resp.Name)), nil
+	}
+
+	mentioned := make([]string, 0)
+	for _, annotation := range resp.Annotations {
+		if annotation.Type == "USER_MENTION" && annotation.UserMention != nil && annotation.UserMention.User != nil {
+			mentioned = append(mentioned, annotation.UserMention.User.Name)
+		}
+	}
+	if len(mentioned) > 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Message sent successfully. Message ID: %s\nMentioned: %s", resp.Name, strings.Join(mentioned, ", "))), nil
+	}
+
 	return mcp.NewToolResultText(fmt.Sprintf("Message sent successfully. Message ID: %s", resp.Name)), nil
 }
 
+// parseAccessoryWidgets decodes and validates a JSON array of accessory
+// widgets, rejecting unknown fields and buttons that can do nothing so
+// mistakes are reported before the message is sent.
+func parseAccessoryWidgets(widgetsJSON string) ([]*chat.AccessoryWidget, error) {
+	decoder := json.NewDecoder(strings.NewReader(widgetsJSON))
+	decoder.DisallowUnknownFields()
+
+	var widgets []*chat.AccessoryWidget
+	if err := decoder.Decode(&widgets); err != nil {
+		return nil, fmt.Errorf("malformed JSON: %v", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected content after the JSON array")
+	}
+	if len(widgets) == 0 {
+		return nil, fmt.Errorf("at least one widget is required")
+	}
+
+	for i, widget := range widgets {
+		if widget == nil || widget.ButtonList == nil || len(widget.ButtonList.Buttons) == 0 {
+			return nil, fmt.Errorf("widget %d: buttonList with at least one button is required", i)
+		}
+		for j, button := range widget.ButtonList.Buttons {
+			if button == nil {
+				return nil, fmt.Errorf("widget %d, button %d: button is empty", i, j)
+			}
+			if button.Text == "" && button.Icon == nil {
+				return nil, fmt.Errorf("widget %d, button %d: text or icon is required", i, j)
+			}
+			if button.OnClick == nil {
+				return nil, fmt.Errorf("widget %d, button %d: onClick is required", i, j)
+			}
+			if button.OnClick.OpenLink != nil && button.OnClick.OpenLink.Url == "" {
+				return nil, fmt.Errorf("widget %d, button %d: onClick.openLink.url is required", i, j)
+			}
+		}
+	}
+	return widgets, nil
+}
+
+// applyMentions rewrites message so Chat turns each user in the
+// comma-separated mentions list into a USER_MENTION annotation. Chat only
+// notifies users referenced with the <users/{user}> syntax, which the server
+// converts into annotations with the right offsets; plain "@name" text does
+// nothing. A literal "@email" or "@localpart" is replaced in place, and users
+// without one are mentioned at the start of the message.
+func applyMentions(message string, mentions string) string {
+	prefix := make([]string, 0)
+	for _, mention := range strings.Split(mentions, ",") {
+		mention = strings.TrimSpace(mention)
+		if mention == "" {
+			continue
+		}
+
+		user := strings.TrimPrefix(mention, "users/")
+		token := fmt.Sprintf("<users/%s>", user)
+
+		candidates := []string{"@" + user}
+		if at := strings.Index(user, "@"); at > 0 {
+			candidates = append(candidates, "@"+user[:at])
+		}
+
+		replaced := false
+		for _, candidate := range candidates {
+			if strings.Contains(message, candidate) {
+				message = strings.Replace(message, candidate, token, 1)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			prefix = append(prefix, token)
+		}
+	}
+
+	if len(prefix) > 0 {
+		message = strings.Join(prefix, " ") + " " + message
+	}
+	return message
+}
+
+// withChatFormat adds the optional "format" argument selecting how message
+// text is interpreted.
+func withChatFormat() mcp.ToolOption {
+	return mcp.WithString("format", mcp.Description("How to interpret the message: plain (sent as is) or markdown (converted to Chat formatting: **bold**, *italic*, ~~strike~~, `code`, fenced code blocks, bullet lists, # headings, [text](url) links; tables and images are not supported)"))
+}
+
+// chatFormatArg reports whether the message should be converted from
+// markdown, honoring the older use_markdown flag when format is not given.
+func chatFormatArg(arguments map[string]interface{}) (bool, error) {
+	format, _ := arguments["format"].(string)
+	switch format {
+	case "":
+		useMarkdown, _ := arguments["use_markdown"].(bool)
+		return useMarkdown, nil
+	case "plain":
+		return false, nil
+	case "markdown":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Invalid format. Must be one of: plain, markdown")
+	}
+}
+
+// newChatMessage builds a text message, converting standard markdown into
+// Chat's formatting syntax when useMarkdown is set.
+func newChatMessage(message string, useMarkdown bool) *chat.Message {
+	if useMarkdown {
+		message = util.MarkdownToChat(message)
+	}
+
+	return &chat.Message{
+		Text: message,
+	}
+}
+
+// maxBroadcastConcurrency bounds the number of simultaneous sends in gchat_broadcast.
+const maxBroadcastConcurrency = 5
+
+func gChatBroadcastHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	spaceNamesStr, _ := arguments["space_names"].(string)
+	message, _ := arguments["message"].(string)
+	useMarkdown, err := chatFormatArg(arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if message == "" {
+		return mcp.NewToolResultError("message is required"), nil
+	}
+
+	spaceNames := make([]string, 0)
+	for _, name := range strings.Split(spaceNamesStr, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			spaceNames = append(spaceNames, name)
+		}
+	}
+	if len(spaceNames) == 0 {
+		return mcp.NewToolResultError("space_names must contain at least one space"), nil
+	}
+
+	// A fixed request ID per space makes a retried create return the message
+	// already posted instead of posting it again
+	requestIDs := make(map[string]string, len(spaceNames))
+	for _, spaceName := range spaceNames {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to generate request ID: %v", err)), nil
+		}
+		requestIDs[spaceName] = hex.EncodeToString(random)
+	}
+
+	sent, errs := util.RunBatch(spaceNames, maxBroadcastConcurrency, func(spaceName string) (*chat.Message, error) {
+		return gchatService(profile).Spaces.Messages.Create(spaceName, newChatMessage(message, useMarkdown)).
+			RequestId(requestIDs[spaceName]).Do()
+	})
+
+	results := make([]map[string]interface{}, len(spaceNames))
+	for i, spaceName := range spaceNames {
+		switch {
+		case errors.Is(errs[i], util.ErrBatchSkipped):
+			results[i] = map[string]interface{}{"space": spaceName, "success": false, "skipped": true}
+		case errs[i] != nil:
+			results[i] = map[string]interface{}{"space": spaceName, "success": false, "error": errs[i].Error()}
+		default:
+			results[i] = map[string]interface{}{"space": spaceName, "success": true, "messageId": sent[i].Name}
+		}
+	}
+
+	succeeded, failed, skipped := util.BatchCounts(errs)
+	result := map[string]interface{}{
+		"total":     len(spaceNames),
+		"succeeded": succeeded,
+		"failed":    failed,
+		"results":   results,
+	}
+	if skipped > 0 {
+		result["skipped"] = skipped
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
 func gChatListUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	// Get all spaces
-	spaces, err := services.DefaultGChatService().Spaces.List().Do()
+	spaces, err := gchatService(profile).Spaces.List().Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list spaces: %v", err)), nil
 	}
@@ -155,7 +651,7 @@ func gChatListUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	userEmails := make(map[string]map[string]interface{})
 
 	for _, space := range spaces.Spaces {
-		spaceUsers, err := getAllUsersFromSpace(space.Name, space.DisplayName)
+		spaceUsers, err := getAllUsersFromSpace(profile, space.Name, space.DisplayName)
 		if err != nil {
 			// Continue with other spaces if one fails
 			continue
@@ -190,6 +686,7 @@ func gChatListUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 		user["spaceCount"] = len(user["spaces"].([]string))
 		allUsers = append(allUsers, user)
 	}
+	chatUserFields.Select(allUsers)
 
 	result := map[string]interface{}{
 		"users":       allUsers,
@@ -206,13 +703,13 @@ func gChatListUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 }
 
 // Simple helper to get all users from a space
-func getAllUsersFromSpace(spaceName, spaceDisplayName string) ([]map[string]interface{}, error) {
+func getAllUsersFromSpace(profile string, spaceName, spaceDisplayName string) ([]map[string]interface{}, error) {
 	var allUsers []map[string]interface{}
 	pageToken := ""
 
 	for {
 		// Get members with pagination
-		listCall := services.DefaultGChatService().Spaces.Members.List(spaceName).
+		listCall := gchatService(profile).Spaces.Members.List(spaceName).
 			PageSize(1000).
 			ShowGroups(true).
 			UseAdminAccess(true)
@@ -264,43 +761,79 @@ func gChatListAllUsersHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 }
 
 func gChatListMessagesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	spaceName := arguments["space_name"].(string)
 
 	// Handle optional parameters
 	pageSize, ok := arguments["page_size"].(float64)
 	if !ok {
-		pageSize = 100
+		pageSize = float64(util.DefaultPageSizes().ChatMessages)
 	}
 
 	pageToken, _ := arguments["page_token"].(string)
+	includeReactions, _ := arguments["include_reactions"].(bool)
 
-	// Create the list messages request
-	listCall := services.DefaultGChatService().Spaces.Messages.List(spaceName).
-		OrderBy("createTime desc").
-		PageSize(int64(pageSize))
+	filter, err := messageListFilter(arguments, spaceName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if pageToken != "" {
-		listCall = listCall.PageToken(pageToken)
+	maxPages := maxPagesArg(arguments)
+
+	pageMessages := make([]*chat.Message, 0)
+	pagesFetched := 0
+	for pagesFetched < maxPages {
+		// Create the list messages request
+		listCall := gchatService(profile).Spaces.Messages.List(spaceName).
+			OrderBy("createTime desc").
+			PageSize(int64(pageSize))
+
+		if filter != "" {
+			listCall = listCall.Filter(filter)
+		}
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		// Execute the request
+		messages, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get messages: %v", err)), nil
+		}
+		pagesFetched++
+
+		pageMessages = append(pageMessages, messages.Messages...)
+		pageToken = messages.NextPageToken
+		if pageToken == "" {
+			break
+		}
 	}
 
-	// Execute the request
-	messages, err := listCall.Do()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get messages: %v", err)), nil
+	senderNames := map[string]string{}
+	if includeSenderNames, _ := arguments["include_sender_names"].(bool); includeSenderNames {
+		senderNames = spaceMemberNames(profile, spaceName)
 	}
 
 	result := map[string]interface{}{
 		"messages":      make([]map[string]interface{}, 0),
-		"nextPageToken": messages.NextPageToken,
+		"nextPageToken": pageToken,
 	}
-	for _, msg := range messages.Messages {
+	if maxPages > 1 {
+		addPaginationInfo(result, pagesFetched, pageToken)
+	}
+	for _, msg := range pageMessages {
 
 		messageInfo := map[string]interface{}{
-			"name":       msg.Name,
-			"sender":     msg.Sender,
-			"createTime": msg.CreateTime,
-			"text":       msg.Text,
-			"thread":     msg.Thread,
+			"name":           msg.Name,
+			"sender":         msg.Sender,
+			"createTime":     msg.CreateTime,
+			"text":           msg.Text,
+			"thread":         msg.Thread,
+			"lastUpdateTime": msg.LastUpdateTime,
+			"threadReply":    msg.ThreadReply,
+		}
+		if msg.Sender != nil && senderNames[msg.Sender.Name] != "" {
+			messageInfo["senderName"] = senderNames[msg.Sender.Name]
 		}
 
 		if len(msg.Attachment) > 0 {
@@ -318,10 +851,15 @@ func gChatListMessagesHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 			}
 			messageInfo["attachments"] = attachments
 		}
+
+		if includeReactions && len(msg.EmojiReactionSummaries) > 0 {
+			messageInfo["reactions"] = summarizeReactions(msg.EmojiReactionSummaries)
+		}
 		result["messages"] = append(result["messages"].([]map[string]interface{}), messageInfo)
 	}
+	chatMessageFields.Select(result["messages"].([]map[string]interface{}))
 
-	yamlResult, err := yaml.Marshal(result)
+	yamlResult, err := yaml.Marshal(util.SanitizeValue(result))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal messages: %v", err)), nil
 	}
@@ -329,11 +867,147 @@ func gChatListMessagesHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+// memberNamesTTL is how long a space's member names are cached for sender
+// name resolution.
+const memberNamesTTL = 10 * time.Minute
+
+type cachedMemberNames struct {
+	names   map[string]string
+	fetched time.Time
+}
+
+var memberNamesCache sync.Map
+
+// spaceMemberNames maps the user resource names of a space's members to a
+// readable name: the display name when available, otherwise the email. Results
+// are cached per profile and space. Lookup failures yield an empty map, leaving
+// messages without senderName rather than failing the listing.
+func spaceMemberNames(profile string, spaceName string) map[string]string {
+	key := profile + "|" + spaceName
+	if cached, ok := memberNamesCache.Load(key); ok {
+		if entry := cached.(cachedMemberNames); time.Since(entry.fetched) < memberNamesTTL {
+			return entry.names
+		}
+	}
+
+	names := make(map[string]string)
+	pageToken := ""
+	for {
+		listCall := gchatService(profile).Spaces.Members.List(spaceName).PageSize(1000)
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		members, err := listCall.Do()
+		if err != nil {
+			log.Printf("Failed to list members of %s: %v", spaceName, err)
+			return names
+		}
+
+		for _, member := range members.Memberships {
+			if member.Member == nil {
+				continue
+			}
+			name := member.Member.DisplayName
+			if name == "" {
+				if email := strings.TrimPrefix(member.Member.Name, "users/"); strings.Contains(email, "@") {
+					name = email
+				}
+			}
+			if name != "" {
+				names[member.Member.Name] = name
+			}
+		}
+
+		pageToken = members.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	memberNamesCache.Store(key, cachedMemberNames{names: names, fetched: time.Now()})
+	return names
+}
+
+// messageListFilter composes a Chat API message filter from the since, until
+// and thread_name arguments, validating them first since the API only reports
+// a generic invalid-argument error for a bad filter.
+func messageListFilter(arguments map[string]interface{}, spaceName string) (string, error) {
+	conditions := make([]string, 0, 3)
+
+	var sinceTime, untilTime time.Time
+	if since, _ := arguments["since"].(string); since != "" {
+		parsed, err := util.ParseTime(since)
+		if err != nil {
+			return "", fmt.Errorf("invalid since: %v", err)
+		}
+		sinceTime = parsed
+		conditions = append(conditions, fmt.Sprintf("createTime > %s", quoteFilterValue(sinceTime.Format(time.RFC3339))))
+	}
+	if until, _ := arguments["until"].(string); until != "" {
+		parsed, err := util.ParseTime(until)
+		if err != nil {
+			return "", fmt.Errorf("invalid until: %v", err)
+		}
+		untilTime = parsed
+		conditions = append(conditions, fmt.Sprintf("createTime < %s", quoteFilterValue(untilTime.Format(time.RFC3339))))
+	}
+	if !sinceTime.IsZero() && !untilTime.IsZero() && !sinceTime.Before(untilTime) {
+		return "", fmt.Errorf("since must be before until")
+	}
+
+	if threadName, _ := arguments["thread_name"].(string); threadName != "" {
+		threadName = strings.TrimSpace(threadName)
+		space, _, ok := strings.Cut(threadName, "/threads/")
+		if !ok || !strings.HasPrefix(space, "spaces/") || strings.HasSuffix(threadName, "/threads/") {
+			return "", fmt.Errorf("invalid thread_name %q: expected spaces/{space}/threads/{thread}", threadName)
+		}
+		if space != spaceName {
+			return "", fmt.Errorf("thread_name %s is not in space %s", threadName, spaceName)
+		}
+		conditions = append(conditions, fmt.Sprintf("thread.name = %s", quoteFilterValue(threadName)))
+	}
+
+	return strings.Join(conditions, " AND "), nil
+}
+
+// quoteFilterValue quotes a value for use in a Chat API list filter, escaping
+// backslashes and double quotes.
+func quoteFilterValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// summarizeReactions converts the reaction summaries returned with each message
+// into emoji/count pairs. The summaries come back as part of the message list
+// response, so no per-message Reactions.List call is needed.
+func summarizeReactions(summaries []*chat.EmojiReactionSummary) []map[string]interface{} {
+	reactions := make([]map[string]interface{}, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.Emoji == nil {
+			continue
+		}
+		emoji := summary.Emoji.Unicode
+		if emoji == "" && summary.Emoji.CustomEmoji != nil {
+			emoji = "custom:" + summary.Emoji.CustomEmoji.Uid
+		}
+		reactions = append(reactions, map[string]interface{}{
+			"emoji": emoji,
+			"count": summary.ReactionCount,
+		})
+	}
+	return reactions
+}
+
 func gChatCreateThreadHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	displayName := arguments["display_name"].(string)
 	userEmails := arguments["user_emails"].(string)
 	initialMessage, hasInitialMessage := arguments["initial_message"].(string)
 	externalUserAllowed, _ := arguments["external_user_allowed"].(bool)
+	permissionSettings, err := predefinedPermissionSettingsArg(arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	// Parse user emails
 	emails := strings.Split(userEmails, ",")
@@ -353,7 +1027,12 @@ func gChatCreateThreadHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	}
 
 	// Create the space
-	createdSpace, err := services.DefaultGChatService().Spaces.Create(space).Do()
+	var createdSpace *chat.Space
+	if permissionSettings != "" {
+		createdSpace, err = createSpaceWithPermissionSettings(profile, space, permissionSettings)
+	} else {
+		createdSpace, err = gchatService(profile).Spaces.Create(space).Do()
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create space: %v", err)), nil
 	}
@@ -374,7 +1053,7 @@ func gChatCreateThreadHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 			},
 		}
 
-		_, err := services.DefaultGChatService().Spaces.Members.Create(createdSpace.Name, member).Do()
+		_, err := gchatService(profile).Spaces.Members.Create(createdSpace.Name, member).Do()
 		if err != nil {
 			failedMembers = append(failedMembers, fmt.Sprintf("%s: %v", email, err))
 		} else {
@@ -389,7 +1068,7 @@ func gChatCreateThreadHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 			Text: initialMessage,
 		}
 
-		sentMessage, err := services.DefaultGChatService().Spaces.Messages.Create(createdSpace.Name, msg).Do()
+		sentMessage, err := gchatService(profile).Spaces.Messages.Create(createdSpace.Name, msg).Do()
 		if err == nil {
 			messageId = sentMessage.Name
 		}
@@ -410,6 +1089,10 @@ func gChatCreateThreadHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 		},
 	}
 
+	if permissionSettings != "" {
+		result["space"].(map[string]interface{})["permissionSettings"] = permissionSettings
+	}
+
 	if messageId != "" {
 		result["initialMessageId"] = messageId
 	}
@@ -422,11 +1105,82 @@ func gChatCreateThreadHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+// spaceTypePermissionSettings maps the space_type argument to Chat's
+// predefined permission settings.
+var spaceTypePermissionSettings = map[string]string{
+	"collaboration": "COLLABORATION_SPACE",
+	"announcement":  "ANNOUNCEMENT_SPACE",
+}
+
+// predefinedPermissionSettingsArg resolves the space_type and
+// predefined_permission_settings arguments to a predefined permission
+// setting, or "" when neither is given.
+func predefinedPermissionSettingsArg(arguments map[string]interface{}) (string, error) {
+	spaceType, _ := arguments["space_type"].(string)
+	predefined, _ := arguments["predefined_permission_settings"].(string)
+	predefined = strings.ToUpper(strings.TrimSpace(predefined))
+
+	if predefined != "" && predefined != "COLLABORATION_SPACE" && predefined != "ANNOUNCEMENT_SPACE" {
+		return "", fmt.Errorf("Invalid predefined_permission_settings. Must be one of: COLLABORATION_SPACE, ANNOUNCEMENT_SPACE")
+	}
+	if spaceType == "" {
+		return predefined, nil
+	}
+
+	fromType, ok := spaceTypePermissionSettings[strings.ToLower(strings.TrimSpace(spaceType))]
+	if !ok {
+		return "", fmt.Errorf("Invalid space_type. Must be one of: collaboration, announcement")
+	}
+	if predefined != "" && predefined != fromType {
+		return "", fmt.Errorf("space_type %s conflicts with predefined_permission_settings %s", spaceType, predefined)
+	}
+	return fromType, nil
+}
+
+// createSpaceWithPermissionSettings creates a space with predefined permission
+// settings. The Chat client library in use predates the
+// predefinedPermissionSettings field, so the request is sent directly.
+func createSpaceWithPermissionSettings(profile string, space *chat.Space, permissionSettings string) (*chat.Space, error) {
+	spaceJSON, err := json.Marshal(space)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(spaceJSON, &body); err != nil {
+		return nil, err
+	}
+	body["predefinedPermissionSettings"] = permissionSettings
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := services.ProfileHttpClient(profile)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(gchatService(profile).BasePath+"v1/spaces", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	createdSpace := &chat.Space{}
+	if err := json.NewDecoder(resp.Body).Decode(createdSpace); err != nil {
+		return nil, fmt.Errorf("failed to decode created space: %v", err)
+	}
+	return createdSpace, nil
+}
+
 func gChatArchiveThreadHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	spaceName := arguments["space_name"].(string)
 
 	// Get the current space to update it
-	space, err := services.DefaultGChatService().Spaces.Get(spaceName).Do()
+	space, err := gchatService(profile).Spaces.Get(spaceName).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get space: %v", err)), nil
 	}
@@ -436,7 +1190,7 @@ func gChatArchiveThreadHandler(arguments map[string]interface{}) (*mcp.CallToolR
 
 	// Archive the space by updating it
 	// Note: Google Chat API uses a PATCH request to update spaces
-	updatedSpace, err := services.DefaultGChatService().Spaces.Patch(spaceName, space).
+	updatedSpace, err := gchatService(profile).Spaces.Patch(spaceName, space).
 		UpdateMask("spaceHistoryState").Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to archive space: %v", err)), nil
@@ -460,22 +1214,27 @@ func gChatArchiveThreadHandler(arguments map[string]interface{}) (*mcp.CallToolR
 }
 
 func gChatGetThreadMessagesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	spaceName := arguments["space_name"].(string)
 	threadName := arguments["thread_name"].(string)
 
+	if !strings.HasPrefix(threadName, spaceName+"/threads/") {
+		return mcp.NewToolResultError(fmt.Sprintf("thread %s does not belong to space %s; expected a name like %s/threads/THREAD_ID", threadName, spaceName, spaceName)), nil
+	}
+
 	// Handle optional parameters
 	pageSize, ok := arguments["page_size"].(float64)
 	if !ok {
-		pageSize = 100
+		pageSize = float64(util.DefaultPageSizes().ChatMessages)
 	}
 
 	pageToken, _ := arguments["page_token"].(string)
 
 	// Create the list messages request with thread filter
-	listCall := services.DefaultGChatService().Spaces.Messages.List(spaceName).
+	listCall := gchatService(profile).Spaces.Messages.List(spaceName).
 		OrderBy("createTime desc").
 		PageSize(int64(pageSize)).
-		Filter(fmt.Sprintf("thread.name = %s", threadName))
+		Filter(fmt.Sprintf("thread.name = %s", quoteFilterValue(threadName)))
 
 	if pageToken != "" {
 		listCall = listCall.PageToken(pageToken)
@@ -487,6 +1246,11 @@ func gChatGetThreadMessagesHandler(arguments map[string]interface{}) (*mcp.CallT
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get thread messages: %v", err)), nil
 	}
 
+	senderNames := map[string]string{}
+	if includeSenderNames, _ := arguments["include_sender_names"].(bool); includeSenderNames {
+		senderNames = spaceMemberNames(profile, spaceName)
+	}
+
 	result := map[string]interface{}{
 		"messages":      make([]map[string]interface{}, 0),
 		"nextPageToken": messages.NextPageToken,
@@ -495,11 +1259,16 @@ func gChatGetThreadMessagesHandler(arguments map[string]interface{}) (*mcp.CallT
 
 	for _, msg := range messages.Messages {
 		messageInfo := map[string]interface{}{
-			"name":       msg.Name,
-			"sender":     msg.Sender,
-			"createTime": msg.CreateTime,
-			"text":       msg.Text,
-			"thread":     msg.Thread,
+			"name":           msg.Name,
+			"sender":         msg.Sender,
+			"createTime":     msg.CreateTime,
+			"text":           msg.Text,
+			"thread":         msg.Thread,
+			"lastUpdateTime": msg.LastUpdateTime,
+			"threadReply":    msg.ThreadReply,
+		}
+		if msg.Sender != nil && senderNames[msg.Sender.Name] != "" {
+			messageInfo["senderName"] = senderNames[msg.Sender.Name]
 		}
 
 		if len(msg.Attachment) > 0 {
@@ -519,8 +1288,9 @@ func gChatGetThreadMessagesHandler(arguments map[string]interface{}) (*mcp.CallT
 		}
 		result["messages"] = append(result["messages"].([]map[string]interface{}), messageInfo)
 	}
+	chatMessageFields.Select(result["messages"].([]map[string]interface{}))
 
-	yamlResult, err := yaml.Marshal(result)
+	yamlResult, err := yaml.Marshal(util.SanitizeValue(result))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal thread messages: %v", err)), nil
 	}
@@ -529,10 +1299,16 @@ func gChatGetThreadMessagesHandler(arguments map[string]interface{}) (*mcp.CallT
 }
 
 func gChatDeleteThreadHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	spaceName := arguments["space_name"].(string)
 
+	impact := fmt.Sprintf("Permanently deletes space %s with all of its messages and memberships. This cannot be undone.", spaceName)
+	if confirm := requireConfirmation(arguments, "gchat_delete_thread:"+profile+":"+spaceName, impact); confirm != nil {
+		return confirm, nil
+	}
+
 	// Delete the space
-	_, err := services.DefaultGChatService().Spaces.Delete(spaceName).Do()
+	_, err := gchatService(profile).Spaces.Delete(spaceName).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to delete space: %v", err)), nil
 	}
@@ -551,14 +1327,254 @@ func gChatDeleteThreadHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-func findUserInSpaces(targetUserID string) (map[string]interface{}, bool, error) {
-	spaces, err := services.DefaultGChatService().Spaces.List().Do()
+func gChatDeleteMessageHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	messageName, _ := arguments["message_name"].(string)
+	checkThread, _ := arguments["check_thread"].(bool)
+
+	spaceName, _, found := strings.Cut(messageName, "/messages/")
+	if !found || !strings.HasPrefix(spaceName, "spaces/") {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid message_name %q; expected a name like spaces/SPACE_ID/messages/MESSAGE_ID", messageName)), nil
+	}
+
+	impact := fmt.Sprintf("Permanently deletes message %s. This cannot be undone.", messageName)
+	if confirm := requireConfirmation(arguments, "gchat_delete_message:"+profile+":"+messageName, impact); confirm != nil {
+		return confirm, nil
+	}
+
+	// Look up the thread before deleting, since the message is gone afterwards
+	var threadName string
+	if checkThread {
+		msg, err := gchatService(profile).Spaces.Messages.Get(messageName).Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get message: %v", err)), nil
+		}
+		if msg.Thread != nil {
+			threadName = msg.Thread.Name
+		}
+	}
+
+	_, err := gchatService(profile).Spaces.Messages.Delete(messageName).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete message: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"messageName": messageName,
+		"deleted":     true,
+	}
+
+	if threadName != "" {
+		result["threadName"] = threadName
+		remaining, err := gchatService(profile).Spaces.Messages.List(spaceName).
+			PageSize(1).
+			Filter(fmt.Sprintf("thread.name = %s", quoteFilterValue(threadName))).
+			Do()
+		if err != nil {
+			result["threadCheckError"] = fmt.Sprintf("failed to re-query thread: %v", err)
+		} else {
+			result["threadEmpty"] = len(remaining.Messages) == 0
+			if len(remaining.Messages) == 0 {
+				result["note"] = "The thread has no remaining messages. The Chat API cannot delete threads; an empty thread is no longer shown in the space."
+			}
+		}
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gChatDownloadAttachmentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	attachmentName, _ := arguments["attachment_name"].(string)
+	outputPath, _ := arguments["output_path"].(string)
+	if attachmentName == "" || outputPath == "" {
+		return mcp.NewToolResultError("attachment_name and output_path are required"), nil
+	}
+	overwrite, _ := arguments["overwrite"].(bool)
+
+	attachment, err := gchatService(profile).Spaces.Messages.Attachments.Get(attachmentName).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get attachment: %v", err)), nil
+	}
+	if attachment.AttachmentDataRef == nil || attachment.AttachmentDataRef.ResourceName == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("attachment %s has no downloadable data (Drive attachments must be opened from Drive)", attachmentName)), nil
+	}
+
+	resourceName := attachment.AttachmentDataRef.ResourceName
+	size, err := util.DownloadToFile(outputPath, overwrite, func(offset int64) (*http.Response, error) {
+		call := gchatService(profile).Media.Download(resourceName)
+		if offset > 0 {
+			call.Header().Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		return call.Download()
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to download attachment: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"attachmentName": attachmentName,
+		"contentName":    attachment.ContentName,
+		"contentType":    attachment.ContentType,
+		"path":           outputPath,
+		"size":           size,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gChatGetSpaceMembershipHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	spaceName := arguments["space_name"].(string)
+
+	space, err := gchatService(profile).Spaces.Get(spaceName).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get space: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"name":        space.Name,
+		"displayName": space.DisplayName,
+		"spaceType":   space.SpaceType,
+	}
+
+	if space.MembershipCount != nil {
+		result["memberCount"] = map[string]interface{}{
+			"humanUsers": space.MembershipCount.JoinedDirectHumanUserCount,
+			"groups":     space.MembershipCount.JoinedGroupCount,
+		}
+	}
+
+	email, err := authenticatedEmail(profile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	membership, err := gchatService(profile).Spaces.Members.Get(fmt.Sprintf("%s/members/%s", spaceName, email)).Do()
+	if err != nil {
+		result["myRole"] = "NOT_A_MEMBER"
+		result["membershipError"] = err.Error()
+	} else {
+		result["myRole"] = strings.TrimPrefix(membership.Role, "ROLE_")
+		result["myMembershipState"] = membership.State
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gChatResolveUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	userIDsStr, _ := arguments["user_ids"].(string)
+
+	wanted := make([]string, 0)
+	pending := make(map[string]bool)
+	for _, userID := range strings.Split(userIDsStr, ",") {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+		if !strings.HasPrefix(userID, "users/") {
+			userID = "users/" + userID
+		}
+		if !pending[userID] {
+			pending[userID] = true
+			wanted = append(wanted, userID)
+		}
+	}
+	if len(wanted) == 0 {
+		return mcp.NewToolResultError("user_ids is required"), nil
+	}
+
+	// Scan each space's members once, stopping as soon as every requested
+	// user has been seen
+	found := make(map[string]map[string]interface{}, len(wanted))
+	pageToken := ""
+	spacesScanned := 0
+	for len(pending) > 0 {
+		listCall := gchatService(profile).Spaces.List()
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+		spaces, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list spaces: %v", err)), nil
+		}
+
+		for _, space := range spaces.Spaces {
+			if len(pending) == 0 {
+				break
+			}
+			users, err := getAllUsersFromSpace(profile, space.Name, space.DisplayName)
+			if err != nil {
+				continue
+			}
+			spacesScanned++
+			for _, user := range users {
+				name, _ := user["name"].(string)
+				if !pending[name] {
+					continue
+				}
+				delete(user, "role")
+				found[name] = user
+				delete(pending, name)
+			}
+		}
+
+		pageToken = spaces.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	resolved := make([]map[string]interface{}, 0, len(found))
+	notFound := make([]string, 0)
+	for _, userID := range wanted {
+		if user, ok := found[userID]; ok {
+			resolved = append(resolved, user)
+		} else {
+			notFound = append(notFound, userID)
+		}
+	}
+
+	result := map[string]interface{}{
+		"resolved":      resolved,
+		"spacesScanned": spacesScanned,
+	}
+	if len(notFound) > 0 {
+		result["notFound"] = notFound
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal users: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func findUserInSpaces(profile string, targetUserID string) (map[string]interface{}, bool, error) {
+	spaces, err := gchatService(profile).Spaces.List().Do()
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to list spaces: %v", err)
 	}
 
 	for _, space := range spaces.Spaces {
-		members, err := services.DefaultGChatService().Spaces.Members.List(space.Name).
+		members, err := gchatService(profile).Spaces.Members.List(space.Name).
 			PageSize(1000).
 			ShowGroups(true).
 			UseAdminAccess(true).
@@ -582,13 +1598,14 @@ func findUserInSpaces(targetUserID string) (map[string]interface{}, bool, error)
 }
 
 func gChatGetUserInfoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	userID := arguments["user_id"].(string)
 
 	if !strings.HasPrefix(userID, "users/") {
 		return mcp.NewToolResultError("Invalid user ID format. Must start with 'users/'"), nil
 	}
 
-	userInfo, found, err := findUserInSpaces(userID)
+	userInfo, found, err := findUserInSpaces(profile, userID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Error searching for user: %v", err)), nil
 	}
@@ -604,3 +1621,12 @@ func gChatGetUserInfoHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
+
+// gchatService returns the Google Chat service for the given credential profile ("" selects the default account).
+func gchatService(profile string) *chat.Service {
+	srv, err := services.GChatService(profile)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create chat service: %v", err))
+	}
+	return srv
+}
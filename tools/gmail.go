@@ -2,11 +2,20 @@ package tools
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/mail"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"encoding/base64"
 
@@ -24,16 +33,52 @@ func RegisterGmailTools(s *server.MCPServer) {
     searchTool := mcp.NewTool("gmail_search",
         mcp.WithDescription("Search emails in Gmail using Gmail's search syntax"),
         mcp.WithString("query", mcp.Required(), mcp.Description("Gmail search query. Follow Gmail's search syntax")),
+        mcp.WithString("group_by", mcp.Description("Group results instead of returning a flat list: sender_domain, label, day, week")),
+        mcp.WithString("output_format", mcp.Description("Output format: yaml (default) or csv")),
+        withAutoPaginate(),
+        withProfile(),
     )
-    s.AddTool(searchTool, util.ErrorGuard(gmailSearchHandler))
+    s.AddTool(searchTool, util.ErrorGuardNamed(searchTool.Name, gmailSearchHandler))
+
+    // Search threads tool
+    searchThreadsTool := mcp.NewTool("gmail_search_threads",
+        mcp.WithDescription("Search Gmail conversations using Gmail's search syntax, returning one entry per thread with its message count, latest subject/snippet, and participants"),
+        mcp.WithString("query", mcp.Required(), mcp.Description("Gmail search query. Follow Gmail's search syntax")),
+        withAutoPaginate(),
+        withProfile(),
+    )
+    s.AddTool(searchThreadsTool, util.ErrorGuardNamed(searchThreadsTool.Name, gmailSearchThreadsHandler))
+
+    // Triage tool
+    triageTool := mcp.NewTool("gmail_triage",
+        mcp.WithDescription("Rank recent unread emails by how likely they need attention, using the IMPORTANT label, whether you are in To or Cc, whether the sender is someone you email often, and subject keywords. Each result lists the reasons behind its score"),
+        mcp.WithString("query", mcp.Description("Gmail query selecting the messages to rank (default: is:unread newer_than:7d)")),
+        mcp.WithNumber("max_results", mcp.Description("Maximum number of messages to rank (default: 20, max: 100)")),
+        mcp.WithString("keywords", mcp.Description("Comma-separated subject keywords that raise the score (default: urgent, asap, action required, deadline, important, reminder, review, approval, invoice, due)")),
+        withProfile(),
+    )
+    s.AddTool(triageTool, util.ErrorGuardNamed(triageTool.Name, gmailTriageHandler))
 
     // Read email tool
     readEmailTool := mcp.NewTool("gmail_read_email",
         mcp.WithDescription("Read a specific email's full content including headers and body"),
         mcp.WithString("message_id", mcp.Required(), mcp.Description("ID of the email message to read")),
         mcp.WithBoolean("include_attachments", mcp.Description("Whether to include attachment information")),
+        mcp.WithBoolean("include_thread_context", mcp.Description("Also list the other messages in the same thread (from, date, snippet) (default: false)")),
+        withProfile(),
     )
-    s.AddTool(readEmailTool, util.ErrorGuard(gmailReadEmailHandler))
+    s.AddTool(readEmailTool, util.ErrorGuardNamed(readEmailTool.Name, gmailReadEmailHandler))
+
+    // Download attachment tool
+    downloadAttachmentTool := mcp.NewTool("gmail_download_attachment",
+        mcp.WithDescription("Download an email attachment to a local file. Transient failures are retried, and the file only appears once complete"),
+        mcp.WithString("message_id", mcp.Required(), mcp.Description("ID of the email message")),
+        mcp.WithString("attachment_id", mcp.Required(), mcp.Description("Attachment ID from gmail_read_email with include_attachments")),
+        mcp.WithString("output_path", mcp.Required(), mcp.Description("Local file path to save the attachment to. Must not already exist unless overwrite is true")),
+        mcp.WithBoolean("overwrite", mcp.Description("Replace output_path if it already exists (default: false)")),
+        withProfile(),
+    )
+    s.AddTool(downloadAttachmentTool, util.ErrorGuardNamed(downloadAttachmentTool.Name, gmailDownloadAttachmentHandler))
 
     // Reply to email tool
     replyEmailTool := mcp.NewTool("gmail_reply_email",
@@ -41,97 +86,256 @@ func RegisterGmailTools(s *server.MCPServer) {
         mcp.WithString("message_id", mcp.Required(), mcp.Description("ID of the email message to reply to")),
         mcp.WithString("reply_text", mcp.Required(), mcp.Description("Text content of the reply")),
         mcp.WithBoolean("reply_all", mcp.Description("Whether to reply to all recipients")),
+        mcp.WithBoolean("include_quote", mcp.Description("Quote the original message below the reply (default: false)")),
+        withProfile(),
     )
-    s.AddTool(replyEmailTool, util.ErrorGuard(gmailReplyEmailHandler))
+    s.AddTool(replyEmailTool, util.ErrorGuardNamed(replyEmailTool.Name, gmailReplyEmailHandler))
 
     // Move to spam tool
     spamTool := mcp.NewTool("gmail_move_to_spam",
         mcp.WithDescription("Move specific emails to spam folder in Gmail by message IDs"),
         mcp.WithString("message_ids", mcp.Required(), mcp.Description("Comma-separated list of message IDs to move to spam")),
+        withProfile(),
+    )
+    s.AddTool(spamTool, util.ErrorGuardNamed(spamTool.Name, gmailMoveToSpamHandler))
+
+    // Move to inbox tool
+    inboxTool := mcp.NewTool("gmail_move_to_inbox",
+        mcp.WithDescription("Move specific emails back to the inbox in Gmail by message IDs, removing them from spam and trash"),
+        mcp.WithString("message_ids", mcp.Required(), mcp.Description("Comma-separated list of message IDs to move to the inbox")),
+        withProfile(),
+    )
+    s.AddTool(inboxTool, util.ErrorGuardNamed(inboxTool.Name, gmailMoveToInboxHandler))
+
+    // Star tool
+    starTool := mcp.NewTool("gmail_star",
+        mcp.WithDescription("Star or unstar specific emails in Gmail by message IDs"),
+        mcp.WithString("message_ids", mcp.Required(), mcp.Description("Comma-separated list of message IDs to star or unstar")),
+        mcp.WithBoolean("starred", mcp.Description("true to star the messages, false to unstar them (default: true)")),
+        withProfile(),
     )
-    s.AddTool(spamTool, util.ErrorGuard(gmailMoveToSpamHandler))
+    s.AddTool(starTool, util.ErrorGuardNamed(starTool.Name, gmailStarHandler))
+
+    // Apply label by name tool
+    applyLabelTool := mcp.NewTool("gmail_apply_label",
+        mcp.WithDescription("Apply a label to emails by label name, creating the label first if it does not exist"),
+        mcp.WithString("message_ids", mcp.Required(), mcp.Description("Comma-separated list of message IDs to label")),
+        mcp.WithString("name", mcp.Required(), mcp.Description("Label name, e.g. 'Receipts' or 'Projects/Alpha'")),
+        withProfile(),
+    )
+    s.AddTool(applyLabelTool, util.ErrorGuardNamed(applyLabelTool.Name, gmailApplyLabelHandler))
 
     // Unified filter management tool
     filterTool := mcp.NewTool("gmail_filter",
-        mcp.WithDescription("Manage Gmail filters - create, list, or delete filters"),
-        mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: create, list, delete")),
-        mcp.WithString("filter_id", mcp.Description("Filter ID (required for delete action)")),
-        mcp.WithString("from", mcp.Description("Filter emails from this sender (create action)")),
-        mcp.WithString("to", mcp.Description("Filter emails to this recipient (create action)")),
-        mcp.WithString("subject", mcp.Description("Filter emails with this subject (create action)")),
-        mcp.WithString("query", mcp.Description("Additional search query criteria (create action)")),
-        mcp.WithBoolean("add_label", mcp.Description("Add label to matching messages (create action)")),
-        mcp.WithString("label_name", mcp.Description("Name of the label to add (create action, required if add_label is true)")),
-        mcp.WithBoolean("mark_important", mcp.Description("Mark matching messages as important (create action)")),
-        mcp.WithBoolean("mark_read", mcp.Description("Mark matching messages as read (create action)")),
-        mcp.WithBoolean("archive", mcp.Description("Archive matching messages (create action)")),
+        mcp.WithDescription("Manage Gmail filters - create, list, update, delete, or preview which messages filter criteria would match"),
+        mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: create, list, update, delete, preview")),
+        mcp.WithString("filter_id", mcp.Description("Filter ID (required for update/delete actions). Update replaces the filter with a new ID; criteria can be changed but not cleared")),
+        mcp.WithString("from", mcp.Description("Filter emails from this sender (create/update/preview actions)")),
+        mcp.WithString("to", mcp.Description("Filter emails to this recipient (create/update/preview actions)")),
+        mcp.WithString("subject", mcp.Description("Filter emails with this subject (create/update/preview actions)")),
+        mcp.WithString("query", mcp.Description("Additional search query criteria (create/update/preview actions)")),
+        mcp.WithBoolean("add_label", mcp.Description("Add label to matching messages (create/update actions)")),
+        mcp.WithString("label_name", mcp.Description("Name of the label to add (create/update actions, required if add_label is true)")),
+        mcp.WithBoolean("mark_important", mcp.Description("Mark matching messages as important (create/update actions)")),
+        mcp.WithBoolean("mark_read", mcp.Description("Mark matching messages as read (create/update actions)")),
+        mcp.WithBoolean("archive", mcp.Description("Archive matching messages (create/update actions)")),
+        mcp.WithBoolean("apply_to_existing", mcp.Description("Also apply the filter's actions to existing matching messages (create action, default: false)")),
+        mcp.WithNumber("max_results", mcp.Description("Maximum number of sample messages to return (preview action, default: 10)")),
+        withConfirmation(),
+        withProfile(),
     )
-    s.AddTool(filterTool, util.ErrorGuard(gmailFilterHandler))
+    s.AddTool(filterTool, util.ErrorGuardNamed(filterTool.Name, gmailFilterHandler))
 
     // Unified label management tool
     labelTool := mcp.NewTool("gmail_label",
         mcp.WithDescription("Manage Gmail labels - list or delete labels"),
         mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, delete")),
         mcp.WithString("label_id", mcp.Description("Label ID (required for delete action)")),
+        withConfirmation(),
+        withProfile(),
     )
-    s.AddTool(labelTool, util.ErrorGuard(gmailLabelHandler))
+    s.AddTool(labelTool, util.ErrorGuardNamed(labelTool.Name, gmailLabelHandler))
 
+    // Thread info tool
+    threadInfoTool := mcp.NewTool("gmail_thread_info",
+        mcp.WithDescription("Get lightweight metadata for an email thread (participants, timespan, message count, unread state) without message bodies"),
+        mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the email thread")),
+        withProfile(),
+    )
+    s.AddTool(threadInfoTool, util.ErrorGuardNamed(threadInfoTool.Name, gmailThreadInfoHandler))
+
+    // Insert (import) message tool
+    insertTool := mcp.NewTool("gmail_insert",
+        mcp.WithDescription("Insert a raw RFC822 message directly into the mailbox without sending it, e.g. to migrate mail from another system"),
+        mcp.WithString("raw_file", mcp.Description("Path to a file containing the raw RFC822 message (either raw_file or raw_content is required)")),
+        mcp.WithString("raw_content", mcp.Description("Raw RFC822 message content (either raw_file or raw_content is required)")),
+        mcp.WithString("label_ids", mcp.Description("Comma-separated list of label IDs to apply (e.g. INBOX,UNREAD)")),
+        mcp.WithString("internal_date_source", mcp.Description("Source for Gmail's internal date: receivedTime or dateHeader (default: receivedTime)")),
+        withProfile(),
+    )
+    s.AddTool(insertTool, util.ErrorGuardNamed(insertTool.Name, gmailInsertHandler))
+
+    // Vacation responder tool
+    vacationTool := mcp.NewTool("gmail_vacation",
+        mcp.WithDescription("Get, set, or disable the Gmail vacation auto-reply. Gmail applies one continuous start/end window; it cannot limit replies to weekends or other recurring days"),
+        mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: get, set, disable")),
+        mcp.WithString("subject", mcp.Description("Subject of the auto-reply (set action)")),
+        mcp.WithString("message", mcp.Description("Plain-text body of the auto-reply (required for set action)")),
+        mcp.WithString("start", mcp.Description("When replies start: RFC3339, a date (2006-01-02), today, tomorrow, a weekday, or an offset like 'in 2 days' (set action, default: now)")),
+        mcp.WithString("end", mcp.Description("When replies stop, in the same formats as start (set action, default: no end)")),
+        mcp.WithBoolean("restrict_to_contacts", mcp.Description("Only reply to people in your contacts (set action)")),
+        mcp.WithBoolean("restrict_to_domain", mcp.Description("Only reply to people in your domain (set action, Workspace accounts)")),
+        withProfile(),
+    )
+    s.AddTool(vacationTool, util.ErrorGuardNamed(vacationTool.Name, gmailVacationHandler))
+
+    // Mail settings tool
+    settingsTool := mcp.NewTool("gmail_settings",
+        mcp.WithDescription("Get or update Gmail auto-forwarding, IMAP, and POP settings"),
+        mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: get, set")),
+        mcp.WithString("setting", mcp.Required(), mcp.Description("Setting to read or update: forwarding, imap, pop")),
+        mcp.WithBoolean("enabled", mcp.Description("Enable or disable auto-forwarding or IMAP (set action)")),
+        mcp.WithString("forwarding_address", mcp.Description("Verified forwarding address (required to enable auto-forwarding)")),
+        mcp.WithString("disposition", mcp.Description("What to do with forwarded or POP-fetched mail: leaveInInbox, archive, trash, markRead")),
+        mcp.WithBoolean("auto_expunge", mcp.Description("IMAP: expunge messages immediately when marked deleted")),
+        mcp.WithString("expunge_behavior", mcp.Description("IMAP: action for expunged messages: archive, trash, deleteForever")),
+        mcp.WithNumber("max_folder_size", mcp.Description("IMAP: maximum messages per folder shown to clients (0 = no limit)")),
+        mcp.WithString("access_window", mcp.Description("POP: which messages are available: disabled, allMail, fromNowOn")),
+        withProfile(),
+    )
+    s.AddTool(settingsTool, util.ErrorGuardNamed(settingsTool.Name, gmailSettingsHandler))
+
+    // Forwarding tool
+    forwardingTool := mcp.NewTool("gmail_forwarding",
+        mcp.WithDescription("Audit and configure where mail is forwarded: list or get forwarding addresses and their verification status, and get or update auto-forwarding"),
+        mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, get, get_auto_forwarding, update_auto_forwarding")),
+        mcp.WithString("forwarding_address", mcp.Description("Forwarding address (required for get; for update_auto_forwarding, must be verified to enable)")),
+        mcp.WithBoolean("enabled", mcp.Description("Enable or disable auto-forwarding (update_auto_forwarding action)")),
+        mcp.WithString("disposition", mcp.Description("What to do with forwarded mail: leaveInInbox, archive, trash, markRead (update_auto_forwarding action)")),
+        withProfile(),
+    )
+    s.AddTool(forwardingTool, util.ErrorGuardNamed(forwardingTool.Name, gmailForwardingHandler))
 
-}
+    // Label counts tool
+    labelCountsTool := mcp.NewTool("gmail_label_counts",
+        mcp.WithDescription("Get total and unread message/thread counts per Gmail label"),
+        mcp.WithString("labels", mcp.Description("Comma-separated list of label names or IDs to include (default: all labels)")),
+        withProfile(),
+    )
+    s.AddTool(labelCountsTool, util.ErrorGuardNamed(labelCountsTool.Name, gmailLabelCountsHandler))
 
-var gmailService = sync.OnceValue[*gmail.Service](func() *gmail.Service {
-	ctx := context.Background()
+    // Unread summary tool
+    unreadSummaryTool := mcp.NewTool("gmail_unread_summary",
+        mcp.WithDescription("Summarize where unread mail is: every label with unread messages, sorted by unread count"),
+        mcp.WithBoolean("include_empty", mcp.Description("Also list labels with no unread messages (default: false)")),
+        withProfile(),
+    )
+    s.AddTool(unreadSummaryTool, util.ErrorGuardNamed(unreadSummaryTool.Name, gmailUnreadSummaryHandler))
+
+    // Inbox summary tool
+    inboxSummaryTool := mcp.NewTool("gmail_inbox_summary",
+        mcp.WithDescription("Summarize what needs attention in one call: unread or important inbox mail with sender, subject and snippet, grouped by sender or label"),
+        mcp.WithString("group_by", mcp.Description("Group messages by: sender, sender_domain, label (default: sender)")),
+        mcp.WithNumber("max_results", mcp.Description("Maximum number of messages to include (default: 50, max: 100)")),
+        withProfile(),
+    )
+    s.AddTool(inboxSummaryTool, util.ErrorGuardNamed(inboxSummaryTool.Name, gmailInboxSummaryHandler))
 
-    tokenFile := os.Getenv("GOOGLE_TOKEN_FILE")
-	if tokenFile == "" {
-		panic("GOOGLE_TOKEN_FILE environment variable must be set")
-	}
 
-	credentialsFile := os.Getenv("GOOGLE_CREDENTIALS_FILE")
-	if credentialsFile == "" {
-		panic("GOOGLE_CREDENTIALS_FILE environment variable must be set")
-	}
+}
 
-	client := services.GoogleHttpClient(tokenFile, credentialsFile)
+var gmailServices = services.NewProfileCache(func(client *http.Client) (*gmail.Service, error) {
+	return gmail.NewService(context.Background(), option.WithHTTPClient(client))
+})
 
-	srv, err := gmail.NewService(ctx, option.WithHTTPClient(client))
+// gmailService returns the Gmail service for the given credential profile ("" selects the default account).
+func gmailService(profile string) *gmail.Service {
+	srv, err := gmailServices.Get(profile)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create Gmail service: %v", err))
 	}
-
 	return srv
-})
+}
+
+// maxFetchConcurrency bounds the number of simultaneous message fetches.
+const maxFetchConcurrency = 10
+
+// gmailSearchFields selects the fields of searched emails for the configured
+// output verbosity.
+var gmailSearchFields = util.FieldSet{
+	Terse:   []string{"id", "subject"},
+	Verbose: []string{"threadId", "sizeEstimate"},
+}
 
 func gmailSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
     query, ok := arguments["query"].(string)
     if !ok {
         return mcp.NewToolResultError("query must be a string"), nil
     }
 
+    groupBy, _ := arguments["group_by"].(string)
+    switch groupBy {
+    case "", "sender_domain", "label", "day", "week":
+    default:
+        return mcp.NewToolResultError("Invalid group_by. Must be one of: sender_domain, label, day, week"), nil
+    }
+
+    outputFormat, _ := arguments["output_format"].(string)
+    if outputFormat != "" && outputFormat != "yaml" && outputFormat != "csv" {
+        return mcp.NewToolResultError("Invalid output_format. Must be one of: yaml, csv"), nil
+    }
+    if outputFormat == "csv" && groupBy != "" {
+        return mcp.NewToolResultError("output_format csv cannot be combined with group_by"), nil
+    }
+
     user := "me"
     
-    listCall := gmailService().Users.Messages.List(user).Q(query).MaxResults(10)
-    
-    resp, err := listCall.Do()
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("failed to search emails: %v", err)), nil
+    maxPages := maxPagesArg(arguments)
+
+    messages := make([]*gmail.Message, 0)
+    pageToken := ""
+    pagesFetched := 0
+    for pagesFetched < maxPages {
+        listCall := gmailService(profile).Users.Messages.List(user).Q(query).MaxResults(int64(util.DefaultPageSizes().GmailSearch))
+        if pageToken != "" {
+            listCall = listCall.PageToken(pageToken)
+        }
+
+        resp, err := listCall.Do()
+        if err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("failed to search emails: %v", err)), nil
+        }
+        pagesFetched++
+
+        messages = append(messages, resp.Messages...)
+        pageToken = resp.NextPageToken
+        if pageToken == "" {
+            break
+        }
     }
 
     emails := make([]map[string]interface{}, 0)
     
-    for _, msg := range resp.Messages {
-        message, err := gmailService().Users.Messages.Get(user, msg.Id).Do()
-        if err != nil {
-            log.Printf("Failed to get message %s: %v", msg.Id, err)
+    fetched, errs := util.MapConcurrent(messages, maxFetchConcurrency, func(msg *gmail.Message) (*gmail.Message, error) {
+        return gmailService(profile).Users.Messages.Get(user, msg.Id).Do()
+    })
+
+    for i, msg := range messages {
+        if errs[i] != nil {
+            log.Printf("Failed to get message %s: %v", msg.Id, errs[i])
             continue
         }
+        message := fetched[i]
 
         emailInfo := map[string]interface{}{
             "id": msg.Id,
             "snippet": message.Snippet,
+            "threadId": message.ThreadId,
+            "sizeEstimate": message.SizeEstimate,
         }
 
-        for _, header := range message.Payload.Headers {
+        for _, header := range messageHeaders(message) {
             switch header.Name {
             case "From":
                 emailInfo["from"] = header.Value
@@ -142,6 +346,11 @@ func gmailSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
             }
         }
 
+        if groupBy != "" {
+            emailInfo["internalDate"] = message.InternalDate
+            emailInfo["labelIds"] = message.LabelIds
+        }
+
         emails = append(emails, emailInfo)
     }
 
@@ -150,6 +359,35 @@ func gmailSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
         "emails": emails,
     }
 
+    util.SanitizeValue(emails)
+
+    if outputFormat == "csv" {
+        gmailSearchFields.Select(emails)
+        csvResult, err := util.MapsToCSV(emails, gmailSearchFields.Columns("id", "date", "from", "subject", "snippet")...)
+        if err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("failed to format emails as CSV: %v", err)), nil
+        }
+        return mcp.NewToolResultText(csvResult), nil
+    }
+
+    if groupBy != "" {
+        groups, err := groupEmails(profile, emails, groupBy)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        result = map[string]interface{}{
+            "count":   len(emails),
+            "groupBy": groupBy,
+            "groups":  groups,
+        }
+    }
+    // Grouping reads fields terse output drops, so select after grouping
+    gmailSearchFields.Select(emails)
+
+    if maxPages > 1 {
+        addPaginationInfo(result, pagesFetched, pageToken)
+    }
+
     yamlResult, err := yaml.Marshal(result)
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to marshal emails: %v", err)), nil
@@ -158,30 +396,591 @@ func gmailSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
     return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+// gmailThreadFields selects the fields of searched threads for the configured
+// output verbosity.
+var gmailThreadFields = util.FieldSet{
+	Terse:   []string{"id", "subject"},
+	Verbose: []string{"historyId"},
+}
+
+func gmailSearchThreadsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	query, ok := arguments["query"].(string)
+	if !ok {
+		return mcp.NewToolResultError("query must be a string"), nil
+	}
+
+	maxPages := maxPagesArg(arguments)
+
+	threads := make([]*gmail.Thread, 0)
+	pageToken := ""
+	pagesFetched := 0
+	for pagesFetched < maxPages {
+		listCall := gmailService(profile).Users.Threads.List("me").Q(query).MaxResults(int64(util.DefaultPageSizes().GmailSearch))
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		resp, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search threads: %v", err)), nil
+		}
+		pagesFetched++
+
+		threads = append(threads, resp.Threads...)
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	fetched, errs := util.MapConcurrent(threads, maxFetchConcurrency, func(thread *gmail.Thread) (*gmail.Thread, error) {
+		return gmailService(profile).Users.Threads.Get("me", thread.Id).
+			Format("metadata").
+			MetadataHeaders("From", "To", "Cc", "Subject", "Date").
+			Do()
+	})
+
+	summaries := make([]map[string]interface{}, 0, len(threads))
+	for i, thread := range threads {
+		if errs[i] != nil {
+			log.Printf("Failed to get thread %s: %v", thread.Id, errs[i])
+			continue
+		}
+		full := fetched[i]
+
+		summary := map[string]interface{}{
+			"id":           thread.Id,
+			"messageCount": len(full.Messages),
+			"participants": threadParticipants(full),
+			"snippet":      thread.Snippet,
+			"historyId":    full.HistoryId,
+		}
+
+		if len(full.Messages) > 0 {
+			latest := full.Messages[len(full.Messages)-1]
+			if latest.Snippet != "" {
+				summary["snippet"] = latest.Snippet
+			}
+			for _, header := range messageHeaders(latest) {
+				switch header.Name {
+				case "Subject":
+					summary["subject"] = header.Value
+				case "Date":
+					summary["date"] = header.Value
+				}
+			}
+		}
+
+		summaries = append(summaries, summary)
+	}
+	gmailThreadFields.Select(summaries)
+
+	util.SanitizeValue(summaries)
+
+	result := map[string]interface{}{
+		"count":   len(summaries),
+		"threads": summaries,
+	}
+	if maxPages > 1 {
+		addPaginationInfo(result, pagesFetched, pageToken)
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal threads: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// recentEmailsFrom returns the date, subject and snippet of up to limit emails
+// received from sender in the last days days, newest first.
+func recentEmailsFrom(profile string, sender string, days int, limit int) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf("from:%s newer_than:%dd", sender, days)
+	resp, err := gmailService(profile).Users.Messages.List("me").Q(query).MaxResults(int64(limit)).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search emails from %s: %v", sender, err)
+	}
+
+	emails := make([]map[string]interface{}, 0, len(resp.Messages))
+	for _, msg := range resp.Messages {
+		message, err := gmailService(profile).Users.Messages.Get("me", msg.Id).
+			Format("metadata").
+			MetadataHeaders("Subject", "Date").
+			Do()
+		if err != nil {
+			log.Printf("Failed to get message %s: %v", msg.Id, err)
+			continue
+		}
+
+		emailInfo := map[string]interface{}{
+			"id":      msg.Id,
+			"snippet": message.Snippet,
+		}
+		for _, header := range messageHeaders(message) {
+			switch header.Name {
+			case "Subject":
+				emailInfo["subject"] = header.Value
+			case "Date":
+				emailInfo["date"] = header.Value
+			}
+		}
+		emails = append(emails, emailInfo)
+	}
+	return emails, nil
+}
+
+// Triage score weights, reported with the results so the ranking is transparent.
+const (
+	triageImportantScore      = 3
+	triageDirectScore         = 2
+	triageCcScore             = 1
+	triageFrequentSenderScore = 2
+	triageKeywordScore        = 1
+)
+
+var defaultTriageKeywords = []string{"urgent", "asap", "action required", "deadline", "important", "reminder", "review", "approval", "invoice", "due"}
+
+func gmailTriageHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	query, _ := arguments["query"].(string)
+	if query == "" {
+		query = "is:unread newer_than:7d"
+	}
+	maxResults := 20
+	if value, ok := arguments["max_results"].(float64); ok && value > 0 {
+		maxResults = int(value)
+	}
+	if maxResults > 100 {
+		maxResults = 100
+	}
+	keywords := defaultTriageKeywords
+	if keywordsStr, _ := arguments["keywords"].(string); keywordsStr != "" {
+		keywords = make([]string, 0)
+		for _, keyword := range strings.Split(keywordsStr, ",") {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+	}
+
+	self, err := authenticatedEmail(profile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp, err := gmailService(profile).Users.Messages.List("me").Q(query).MaxResults(int64(maxResults)).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search emails: %v", err)), nil
+	}
+
+	frequent, err := frequentContacts(profile)
+	if err != nil {
+		log.Printf("Failed to load frequent contacts: %v", err)
+	}
+
+	fetched, errs := util.MapConcurrent(resp.Messages, maxFetchConcurrency, func(msg *gmail.Message) (*gmail.Message, error) {
+		return gmailService(profile).Users.Messages.Get("me", msg.Id).
+			Format("metadata").
+			MetadataHeaders("From", "To", "Cc", "Subject", "Date").
+			Do()
+	})
+
+	ranked := make([]map[string]interface{}, 0, len(resp.Messages))
+	for i, msg := range resp.Messages {
+		if errs[i] != nil {
+			log.Printf("Failed to get message %s: %v", msg.Id, errs[i])
+			continue
+		}
+		message := fetched[i]
+
+		score := 0
+		reasons := make([]string, 0)
+		emailInfo := map[string]interface{}{
+			"id":       msg.Id,
+			"threadId": message.ThreadId,
+			"snippet":  message.Snippet,
+		}
+
+		for _, labelID := range message.LabelIds {
+			if labelID == "IMPORTANT" {
+				score += triageImportantScore
+				reasons = append(reasons, fmt.Sprintf("marked important by Gmail (+%d)", triageImportantScore))
+				break
+			}
+		}
+
+		var to, cc, subject string
+		for _, header := range messageHeaders(message) {
+			switch header.Name {
+			case "From":
+				emailInfo["from"] = header.Value
+			case "To":
+				to = header.Value
+			case "Cc":
+				cc = header.Value
+			case "Subject":
+				subject = header.Value
+			case "Date":
+				emailInfo["date"] = header.Value
+			}
+		}
+		emailInfo["subject"] = subject
+
+		switch {
+		case addressListContains(to, self):
+			score += triageDirectScore
+			reasons = append(reasons, fmt.Sprintf("sent directly to you (+%d)", triageDirectScore))
+		case addressListContains(cc, self):
+			score += triageCcScore
+			reasons = append(reasons, fmt.Sprintf("you are in Cc (+%d)", triageCcScore))
+		}
+
+		if from, ok := emailInfo["from"].(string); ok {
+			for _, sender := range parseAddressList(from) {
+				if frequent[strings.ToLower(sender.Address)] {
+					score += triageFrequentSenderScore
+					reasons = append(reasons, fmt.Sprintf("from someone you email often (+%d)", triageFrequentSenderScore))
+					break
+				}
+			}
+		}
+
+		lowerSubject := strings.ToLower(subject)
+		for _, keyword := range keywords {
+			if strings.Contains(lowerSubject, keyword) {
+				score += triageKeywordScore
+				reasons = append(reasons, fmt.Sprintf("subject contains %q (+%d)", keyword, triageKeywordScore))
+			}
+		}
+
+		emailInfo["score"] = score
+		emailInfo["reasons"] = reasons
+		ranked = append(ranked, emailInfo)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i]["score"].(int) > ranked[j]["score"].(int)
+	})
+
+	util.SanitizeValue(ranked)
+
+	result := map[string]interface{}{
+		"query":  query,
+		"count":  len(ranked),
+		"emails": ranked,
+		"scoring": map[string]interface{}{
+			"important":          triageImportantScore,
+			"inTo":               triageDirectScore,
+			"inCc":               triageCcScore,
+			"frequentSender":     triageFrequentSenderScore,
+			"perKeyword":         triageKeywordScore,
+			"keywords":           keywords,
+			"frequentSenderRule": "someone you sent mail to at least twice in the last 90 days",
+		},
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal triage results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// frequentContactsTTL is how long each profile's frequent contacts are cached
+// for gmail_triage. They are based on 90 days of sent mail, so they change
+// slowly, while computing them costs up to 201 API calls.
+const frequentContactsTTL = 30 * time.Minute
+
+type cachedFrequentContacts struct {
+	contacts map[string]bool
+	fetched  time.Time
+}
+
+var frequentContactsCache sync.Map
+
+// frequentContacts returns the lower-cased addresses the user sent mail to at
+// least twice in the last 90 days, based on up to 200 sent messages. Results
+// are cached per profile.
+func frequentContacts(profile string) (map[string]bool, error) {
+	if cached, ok := frequentContactsCache.Load(profile); ok {
+		if entry := cached.(cachedFrequentContacts); time.Since(entry.fetched) < frequentContactsTTL {
+			return entry.contacts, nil
+		}
+	}
+
+	resp, err := gmailService(profile).Users.Messages.List("me").Q("in:sent newer_than:90d").MaxResults(200).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sent messages: %v", err)
+	}
+
+	fetched, errs := util.MapConcurrent(resp.Messages, maxFetchConcurrency, func(msg *gmail.Message) (*gmail.Message, error) {
+		return gmailService(profile).Users.Messages.Get("me", msg.Id).
+			Format("metadata").
+			MetadataHeaders("To", "Cc").
+			Do()
+	})
+
+	counts := make(map[string]int)
+	for i := range resp.Messages {
+		if errs[i] != nil {
+			continue
+		}
+		for _, header := range messageHeaders(fetched[i]) {
+			for _, recipient := range parseAddressList(header.Value) {
+				counts[strings.ToLower(recipient.Address)]++
+			}
+		}
+	}
+
+	frequent := make(map[string]bool)
+	for address, count := range counts {
+		if count >= 2 {
+			frequent[address] = true
+		}
+	}
+
+	frequentContactsCache.Store(profile, cachedFrequentContacts{contacts: frequent, fetched: time.Now()})
+	return frequent, nil
+}
+
+// addressListContains reports whether the address header value includes email.
+func addressListContains(value string, email string) bool {
+	for _, address := range parseAddressList(value) {
+		if strings.EqualFold(address.Address, email) {
+			return true
+		}
+	}
+	return false
+}
+
 func gmailMoveToSpamHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
     messageIdsStr, ok := arguments["message_ids"].(string)
     if !ok {
         return mcp.NewToolResultError("message_ids must be a string"), nil
     }
 
-    messageIds := strings.Split(messageIdsStr, ",")
+    messageIds := make([]string, 0)
+    for _, id := range strings.Split(messageIdsStr, ",") {
+        if id = strings.TrimSpace(id); id != "" {
+            messageIds = append(messageIds, id)
+        }
+    }
 
     if len(messageIds) == 0 {
         return mcp.NewToolResultError("no message IDs provided"), nil
     }
 
-    user := "me"
-
-    for _, messageId := range messageIds {
-        _, err := gmailService().Users.Messages.Modify(user, messageId, &gmail.ModifyMessageRequest{
+    // Keep going past failed messages so one bad ID does not hide which
+    // messages were moved
+    _, errs := util.RunBatch(messageIds, maxFetchConcurrency, func(id string) (*gmail.Message, error) {
+        return gmailService(profile).Users.Messages.Modify("me", id, &gmail.ModifyMessageRequest{
             AddLabelIds: []string{"SPAM"},
         }).Do()
-        if err != nil {
-            return mcp.NewToolResultError(fmt.Sprintf("failed to move email %s to spam: %v", messageId, err)), nil
-        }
-    }
+    })
+
+    return messageBatchReport(messageIds, errs, "moved to spam")
+}
+
+func gmailMoveToInboxHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	messageIdsStr, ok := arguments["message_ids"].(string)
+	if !ok {
+		return mcp.NewToolResultError("message_ids must be a string"), nil
+	}
+
+	messageIds := make([]string, 0)
+	for _, id := range strings.Split(messageIdsStr, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			messageIds = append(messageIds, id)
+		}
+	}
+
+	if len(messageIds) == 0 {
+		return mcp.NewToolResultError("no message IDs provided"), nil
+	}
+
+	errs := batchModifyMessages(profile, messageIds, []string{"INBOX"}, []string{"SPAM", "TRASH"})
+	if succeeded, _, _ := util.BatchCounts(errs); succeeded == len(messageIds) {
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully moved %d emails to inbox.", len(messageIds))), nil
+	}
+
+	return messageBatchReport(messageIds, errs, "moved to inbox")
+}
+
+// threadContextFor summarizes the messages of the message's thread other
+// than the message itself, in thread order, along with the message's position
+// in the thread.
+func threadContextFor(profile string, message *gmail.Message) (map[string]interface{}, error) {
+	thread, err := gmailService(profile).Users.Threads.Get("me", message.ThreadId).
+		Format("metadata").
+		MetadataHeaders("From", "Date").
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread: %v", err)
+	}
+
+	others := make([]map[string]interface{}, 0, len(thread.Messages))
+	position := 0
+	for i, threadMessage := range thread.Messages {
+		if threadMessage.Id == message.Id {
+			position = i + 1
+			continue
+		}
+		info := map[string]interface{}{
+			"id":      threadMessage.Id,
+			"snippet": threadMessage.Snippet,
+		}
+		for _, header := range messageHeaders(threadMessage) {
+			switch header.Name {
+			case "From":
+				info["from"] = header.Value
+			case "Date":
+				info["date"] = header.Value
+			}
+		}
+		others = append(others, info)
+	}
+
+	return map[string]interface{}{
+		"threadId":      thread.Id,
+		"position":      fmt.Sprintf("%d of %d", position, len(thread.Messages)),
+		"otherMessages": others,
+	}, nil
+}
+
+func gmailStarHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	messageIdsStr, ok := arguments["message_ids"].(string)
+	if !ok {
+		return mcp.NewToolResultError("message_ids must be a string"), nil
+	}
+	starred := true
+	if value, ok := arguments["starred"].(bool); ok {
+		starred = value
+	}
+
+	messageIds := make([]string, 0)
+	for _, id := range strings.Split(messageIdsStr, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			messageIds = append(messageIds, id)
+		}
+	}
+
+	if len(messageIds) == 0 {
+		return mcp.NewToolResultError("no message IDs provided"), nil
+	}
 
-    return mcp.NewToolResultText(fmt.Sprintf("Successfully moved %d emails to spam.", len(messageIds))), nil
+	request := &gmail.ModifyMessageRequest{}
+	status := "starred"
+	if starred {
+		request.AddLabelIds = []string{"STARRED"}
+	} else {
+		request.RemoveLabelIds = []string{"STARRED"}
+		status = "unstarred"
+	}
+
+	_, errs := util.RunBatch(messageIds, maxFetchConcurrency, func(id string) (*gmail.Message, error) {
+		return gmailService(profile).Users.Messages.Modify("me", id, request).Do()
+	})
+
+	return messageBatchReport(messageIds, errs, status)
+}
+
+// messageBatchReport reports the outcome of a per-message batch operation:
+// status for each message that succeeded, and the error or skip for the rest.
+func messageBatchReport(messageIds []string, errs []error, status string) (*mcp.CallToolResult, error) {
+	results := make([]map[string]string, 0, len(messageIds))
+	for i, id := range messageIds {
+		switch {
+		case errors.Is(errs[i], util.ErrBatchSkipped):
+			results = append(results, map[string]string{"id": id, "status": "skipped"})
+		case errs[i] != nil:
+			results = append(results, map[string]string{"id": id, "status": "failed", "error": errs[i].Error()})
+		default:
+			results = append(results, map[string]string{"id": id, "status": status})
+		}
+	}
+
+	succeeded, failed, skipped := util.BatchCounts(errs)
+	result := map[string]interface{}{
+		"succeeded": succeeded,
+		"failed":    failed,
+		"skipped":   skipped,
+		"results":   results,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// maxBatchModifyIDs is the most message IDs a single BatchModify call accepts.
+const maxBatchModifyIDs = 1000
+
+// batchModifyMessages adds and removes labels on messages with BatchModify,
+// in chunks of at most maxBatchModifyIDs. Each chunk succeeds or fails as a
+// whole, so the returned errors, one per message in input order, carry the
+// error of the message's chunk.
+func batchModifyMessages(profile string, messageIds []string, addLabelIds []string, removeLabelIds []string) []error {
+	chunks := make([][]string, 0)
+	for start := 0; start < len(messageIds); start += maxBatchModifyIDs {
+		chunks = append(chunks, messageIds[start:min(start+maxBatchModifyIDs, len(messageIds))])
+	}
+	_, errs := util.RunBatch(chunks, 1, func(ids []string) (struct{}, error) {
+		return struct{}{}, gmailService(profile).Users.Messages.BatchModify("me", &gmail.BatchModifyMessagesRequest{
+			Ids:            ids,
+			AddLabelIds:    addLabelIds,
+			RemoveLabelIds: removeLabelIds,
+		}).Do()
+	})
+
+	messageErrs := make([]error, 0, len(messageIds))
+	for i, chunk := range chunks {
+		for range chunk {
+			messageErrs = append(messageErrs, errs[i])
+		}
+	}
+	return messageErrs
+}
+
+func gmailApplyLabelHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	messageIdsStr, _ := arguments["message_ids"].(string)
+	name, _ := arguments["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+
+	messageIds := make([]string, 0)
+	for _, id := range strings.Split(messageIdsStr, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			messageIds = append(messageIds, id)
+		}
+	}
+
+	if len(messageIds) == 0 {
+		return mcp.NewToolResultError("no message IDs provided"), nil
+	}
+
+	label, err := createOrGetLabel(profile, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	messageErrs := batchModifyMessages(profile, messageIds, []string{label.Id}, nil)
+	if succeeded, _, _ := util.BatchCounts(messageErrs); succeeded == len(messageIds) {
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully applied label %s (ID: %s) to %d emails.", label.Name, label.Id, len(messageIds))), nil
+	}
+
+	return messageBatchReport(messageIds, messageErrs, "labeled "+label.Name)
 }
 
 func gmailFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
@@ -192,14 +991,19 @@ func gmailFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		return gmailCreateFilterHandler(arguments)
 	case "list":
 		return gmailListFiltersHandler(arguments)
+	case "update":
+		return gmailUpdateFilterHandler(arguments)
 	case "delete":
 		return gmailDeleteFilterHandler(arguments)
+	case "preview":
+		return gmailPreviewFilterHandler(arguments)
 	default:
-		return mcp.NewToolResultError("Invalid action. Must be one of: create, list, delete"), nil
+		return mcp.NewToolResultError("Invalid action. Must be one of: create, list, update, delete, preview"), nil
 	}
 }
 
 func gmailCreateFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
     // Create filter criteria
     criteria := &gmail.FilterCriteria{}
     
@@ -226,7 +1030,7 @@ func gmailCreateFilterHandler(arguments map[string]interface{}) (*mcp.CallToolRe
         }
 
         // First, create or get the label
-        label, err := createOrGetLabel(labelName)
+        label, err := createOrGetLabel(profile, labelName)
         if err != nil {
             return mcp.NewToolResultError(fmt.Sprintf("failed to create/get label: %v", err)), nil
         }
@@ -251,149 +1055,1063 @@ func gmailCreateFilterHandler(arguments map[string]interface{}) (*mcp.CallToolRe
         Action:   action,
     }
 
-    result, err := gmailService().Users.Settings.Filters.Create("me", filter).Do()
+    result, err := gmailService(profile).Users.Settings.Filters.Create("me", filter).Do()
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to create filter: %v", err)), nil
     }
 
+    if applyToExisting, _ := arguments["apply_to_existing"].(bool); applyToExisting {
+        updated, more, err := applyFilterToExisting(profile, filter)
+        if err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("created filter with ID %s but failed to apply it to existing messages: %v", result.Id, err)), nil
+        }
+        message := fmt.Sprintf("Successfully created filter with ID: %s and applied it to %d existing messages.", result.Id, updated)
+        if more {
+            message += fmt.Sprintf(" More matching messages remain beyond the limit of %d.", util.DefaultPageSizes().GmailFilterApply)
+        }
+        return mcp.NewToolResultText(message), nil
+    }
+
     return mcp.NewToolResultText(fmt.Sprintf("Successfully created filter with ID: %s", result.Id)), nil
 }
 
-func createOrGetLabel(name string) (*gmail.Label, error) {
-    // First try to find existing label
-    labels, err := gmailService().Users.Labels.List("me").Do()
-    if err != nil {
-        return nil, fmt.Errorf("failed to list labels: %v", err)
-    }
+// applyFilterToExisting applies a filter's label actions to the messages that
+// already match its criteria, up to the GmailFilterApply limit. It reports how
+// many messages were updated and whether more matches remained.
+func applyFilterToExisting(profile string, filter *gmail.Filter) (int, bool, error) {
+	if len(filter.Action.AddLabelIds) == 0 && len(filter.Action.RemoveLabelIds) == 0 {
+		return 0, false, nil
+	}
 
-    for _, label := range labels.Labels {
-        if label.Name == name {
-            return label, nil
-        }
-    }
+	query := filterSearchQuery(filter.Criteria)
+	if query == "" {
+		return 0, false, fmt.Errorf("filter has no criteria to match")
+	}
+
+	limit := util.DefaultPageSizes().GmailFilterApply
+	messageIds := make([]string, 0)
+	pageToken := ""
+	for len(messageIds) < limit {
+		listCall := gmailService(profile).Users.Messages.List("me").Q(query).MaxResults(int64(min(limit-len(messageIds), 500)))
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		resp, err := listCall.Do()
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to search matching messages: %v", err)
+		}
+
+		for _, msg := range resp.Messages {
+			messageIds = append(messageIds, msg.Id)
+		}
+
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	// BatchModify accepts at most 1000 IDs per call
+	for start := 0; start < len(messageIds); start += 1000 {
+		end := min(start+1000, len(messageIds))
+		err := gmailService(profile).Users.Messages.BatchModify("me", &gmail.BatchModifyMessagesRequest{
+			Ids:            messageIds[start:end],
+			AddLabelIds:    filter.Action.AddLabelIds,
+			RemoveLabelIds: filter.Action.RemoveLabelIds,
+		}).Do()
+		if err != nil {
+			return start, pageToken != "", fmt.Errorf("failed to modify messages: %v", err)
+		}
+	}
+
+	return len(messageIds), pageToken != "", nil
+}
+
+// gmailUpdateFilterHandler replaces a filter with a copy that merges the
+// requested criteria and action changes. Gmail has no filter update call, so
+// the original is deleted and the merged filter created; if creation fails the
+// original filter is restored.
+func gmailUpdateFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	filterID, _ := arguments["filter_id"].(string)
+	if filterID == "" {
+		return mcp.NewToolResultError("filter_id is required for update action"), nil
+	}
+
+	original, err := gmailService(profile).Users.Settings.Filters.Get("me", filterID).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get filter: %v", err)), nil
+	}
+
+	criteria := &gmail.FilterCriteria{}
+	if original.Criteria != nil {
+		*criteria = *original.Criteria
+	}
+	action := &gmail.FilterAction{}
+	if original.Action != nil {
+		*action = *original.Action
+		action.AddLabelIds = append([]string{}, original.Action.AddLabelIds...)
+		action.RemoveLabelIds = append([]string{}, original.Action.RemoveLabelIds...)
+	}
+
+	if from, ok := arguments["from"].(string); ok && from != "" {
+		criteria.From = from
+	}
+	if to, ok := arguments["to"].(string); ok && to != "" {
+		criteria.To = to
+	}
+	if subject, ok := arguments["subject"].(string); ok && subject != "" {
+		criteria.Subject = subject
+	}
+	if query, ok := arguments["query"].(string); ok && query != "" {
+		criteria.Query = query
+	}
+
+	if addLabel, ok := arguments["add_label"].(bool); ok && addLabel {
+		labelName, ok := arguments["label_name"].(string)
+		if !ok || labelName == "" {
+			return mcp.NewToolResultError("label_name is required when add_label is true"), nil
+		}
+
+		label, err := createOrGetLabel(profile, labelName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create/get label: %v", err)), nil
+		}
+		action.AddLabelIds = setLabelID(action.AddLabelIds, label.Id, true)
+	}
+	if markImportant, ok := arguments["mark_important"].(bool); ok {
+		action.AddLabelIds = setLabelID(action.AddLabelIds, "IMPORTANT", markImportant)
+	}
+	if markRead, ok := arguments["mark_read"].(bool); ok {
+		action.RemoveLabelIds = setLabelID(action.RemoveLabelIds, "UNREAD", markRead)
+	}
+	if archive, ok := arguments["archive"].(bool); ok {
+		action.RemoveLabelIds = setLabelID(action.RemoveLabelIds, "INBOX", archive)
+	}
+
+	// Updating replaces the filter, so skip a no-op rather than churn its ID
+	if !filterChanged(original, criteria, action) {
+		return mcp.NewToolResultError(fmt.Sprintf("no changes to filter %s: pass criteria or actions that differ from the current ones (criteria cannot be cleared through update; delete the filter and create a new one instead)", filterID)), nil
+	}
+
+	if err := gmailService(profile).Users.Settings.Filters.Delete("me", filterID).Do(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete original filter: %v", err)), nil
+	}
+
+	updated, err := gmailService(profile).Users.Settings.Filters.Create("me", &gmail.Filter{
+		Criteria: criteria,
+		Action:   action,
+	}).Do()
+	if err != nil {
+		restored, restoreErr := gmailService(profile).Users.Settings.Filters.Create("me", &gmail.Filter{
+			Criteria: original.Criteria,
+			Action:   original.Action,
+		}).Do()
+		if restoreErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create updated filter: %v; restoring the original filter also failed: %v", err, restoreErr)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create updated filter: %v; the original filter was restored with ID: %s", err, restored.Id)), nil
+	}
+
+	result := map[string]interface{}{
+		"oldFilterId": filterID,
+		"newFilterId": updated.Id,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// gmailPreviewFilterHandler runs the search a filter with the given criteria
+// would match and returns a sample of matching messages, so the filter's scope
+// can be checked before it is created.
+func gmailPreviewFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	from, _ := arguments["from"].(string)
+	to, _ := arguments["to"].(string)
+	subject, _ := arguments["subject"].(string)
+	query, _ := arguments["query"].(string)
+
+	maxResults, ok := arguments["max_results"].(float64)
+	if !ok || maxResults <= 0 {
+		maxResults = float64(util.DefaultPageSizes().GmailSearch)
+	}
+
+	searchQuery := filterSearchQuery(&gmail.FilterCriteria{
+		From:    from,
+		To:      to,
+		Subject: subject,
+		Query:   query,
+	})
+	if searchQuery == "" {
+		return mcp.NewToolResultError("at least one of from, to, subject, or query is required for preview action"), nil
+	}
+
+	resp, err := gmailService(profile).Users.Messages.List("me").Q(searchQuery).MaxResults(int64(maxResults)).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search emails: %v", err)), nil
+	}
+
+	samples := make([]map[string]interface{}, 0, len(resp.Messages))
+	for _, msg := range resp.Messages {
+		message, err := gmailService(profile).Users.Messages.Get("me", msg.Id).
+			Format("metadata").
+			MetadataHeaders("From", "To", "Subject", "Date").
+			Do()
+		if err != nil {
+			log.Printf("Failed to get message %s: %v", msg.Id, err)
+			continue
+		}
+
+		sample := map[string]interface{}{
+			"id": msg.Id,
+		}
+		for _, header := range messageHeaders(message) {
+			switch header.Name {
+			case "From":
+				sample["from"] = header.Value
+			case "To":
+				sample["to"] = header.Value
+			case "Subject":
+				sample["subject"] = header.Value
+			case "Date":
+				sample["date"] = header.Value
+			}
+		}
+		samples = append(samples, sample)
+	}
+
+	result := map[string]interface{}{
+		"query":          searchQuery,
+		"estimatedTotal": resp.ResultSizeEstimate,
+		"sampleCount":    len(samples),
+		"samples":        util.SanitizeValue(samples),
+		"hasMore":        resp.NextPageToken != "",
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal preview: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// filterSearchQuery translates filter criteria into the equivalent Gmail
+// search query.
+func filterSearchQuery(criteria *gmail.FilterCriteria) string {
+	terms := make([]string, 0, 4)
+	if criteria.From != "" {
+		terms = append(terms, fmt.Sprintf("from:(%s)", criteria.From))
+	}
+	if criteria.To != "" {
+		terms = append(terms, fmt.Sprintf("to:(%s)", criteria.To))
+	}
+	if criteria.Subject != "" {
+		terms = append(terms, fmt.Sprintf("subject:(%s)", criteria.Subject))
+	}
+	if criteria.Query != "" {
+		terms = append(terms, criteria.Query)
+	}
+	return strings.Join(terms, " ")
+}
+
+// filterChanged reports whether criteria or action differ from the original
+// filter's, ignoring the order of label IDs.
+func filterChanged(original *gmail.Filter, criteria *gmail.FilterCriteria, action *gmail.FilterAction) bool {
+	originalCriteria := &gmail.FilterCriteria{}
+	if original.Criteria != nil {
+		originalCriteria = original.Criteria
+	}
+	originalAction := &gmail.FilterAction{}
+	if original.Action != nil {
+		originalAction = original.Action
+	}
+
+	before, _ := json.Marshal(originalCriteria)
+	after, _ := json.Marshal(criteria)
+	if string(before) != string(after) {
+		return true
+	}
+	if originalAction.Forward != action.Forward {
+		return true
+	}
+	return !sameLabelIDs(originalAction.AddLabelIds, action.AddLabelIds) ||
+		!sameLabelIDs(originalAction.RemoveLabelIds, action.RemoveLabelIds)
+}
+
+// sameLabelIDs reports whether two label ID lists hold the same IDs.
+func sameLabelIDs(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// setLabelID adds id to labelIds when enabled is true, or removes it otherwise.
+func setLabelID(labelIds []string, id string, enabled bool) []string {
+	result := make([]string, 0, len(labelIds)+1)
+	for _, labelId := range labelIds {
+		if labelId != id {
+			result = append(result, labelId)
+		}
+	}
+	if enabled {
+		result = append(result, id)
+	}
+	return result
+}
+
+func createOrGetLabel(profile string, name string) (*gmail.Label, error) {
+    // First try to find existing label
+    labels, err := gmailService(profile).Users.Labels.List("me").Do()
+    if err != nil {
+        return nil, fmt.Errorf("failed to list labels: %v", err)
+    }
+
+    for _, label := range labels.Labels {
+        if label.Name == name {
+            return label, nil
+        }
+    }
+
+    // If not found, create new label
+    newLabel := &gmail.Label{
+        Name:                  name,
+        MessageListVisibility: "show",
+        LabelListVisibility:   "labelShow",
+    }
+
+    label, err := gmailService(profile).Users.Labels.Create("me", newLabel).Do()
+    if err != nil {
+        return nil, fmt.Errorf("failed to create label: %v", err)
+    }
+
+    return label, nil
+}
+
+// gmailFilterFields selects the fields of listed filters for the configured
+// output verbosity.
+var gmailFilterFields = util.FieldSet{
+	Terse: []string{"id", "criteria"},
+}
+
+func gmailListFiltersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+    filters, err := gmailService(profile).Users.Settings.Filters.List("me").Do()
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("failed to list filters: %v", err)), nil
+    }
+
+    filtersResult := make([]map[string]interface{}, 0)
+    
+    for _, filter := range filters.Filter {
+        filterInfo := map[string]interface{}{
+            "id": filter.Id,
+            "criteria": map[string]string{},
+            "actions": map[string]interface{}{},
+        }
+        
+        if filter.Criteria == nil {
+            filter.Criteria = &gmail.FilterCriteria{}
+        }
+        if filter.Action == nil {
+            filter.Action = &gmail.FilterAction{}
+        }
+
+        // Add criteria
+        if filter.Criteria.From != "" {
+            filterInfo["criteria"].(map[string]string)["from"] = filter.Criteria.From
+        }
+        if filter.Criteria.To != "" {
+            filterInfo["criteria"].(map[string]string)["to"] = filter.Criteria.To
+        }
+        if filter.Criteria.Subject != "" {
+            filterInfo["criteria"].(map[string]string)["subject"] = filter.Criteria.Subject
+        }
+        if filter.Criteria.Query != "" {
+            filterInfo["criteria"].(map[string]string)["query"] = filter.Criteria.Query
+        }
+
+        // Add actions
+        if len(filter.Action.AddLabelIds) > 0 {
+            filterInfo["actions"].(map[string]interface{})["addLabels"] = filter.Action.AddLabelIds
+        }
+        if len(filter.Action.RemoveLabelIds) > 0 {
+            filterInfo["actions"].(map[string]interface{})["removeLabels"] = filter.Action.RemoveLabelIds
+        }
+        
+        filtersResult = append(filtersResult, filterInfo)
+    }
+    gmailFilterFields.Select(filtersResult)
+
+    result := map[string]interface{}{
+        "count": len(filtersResult),
+        "filters": filtersResult,
+    }
+
+    yamlResult, err := yaml.Marshal(result)
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("failed to marshal filters: %v", err)), nil
+    }
+
+    return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gmailLabelHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	action, _ := arguments["action"].(string)
+	
+	switch action {
+	case "list":
+		return gmailListLabelsHandler(arguments)
+	case "delete":
+		return gmailDeleteLabelHandler(arguments)
+	default:
+		return mcp.NewToolResultError("Invalid action. Must be one of: list, delete"), nil
+	}
+}
+
+// gmailLabelFields selects the fields of listed labels for the configured
+// output verbosity.
+var gmailLabelFields = util.FieldSet{
+	Terse: []string{"id", "name"},
+}
+
+func gmailListLabelsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+    labels, err := gmailService(profile).Users.Labels.List("me").Do()
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
+    }
+
+    systemLabels := make([]map[string]interface{}, 0)
+    userLabels := make([]map[string]interface{}, 0)
+
+    for _, label := range labels.Labels {
+        labelInfo := map[string]interface{}{
+            "id": label.Id,
+            "name": label.Name,
+        }
+        
+        if label.MessagesTotal > 0 {
+            labelInfo["messagesTotal"] = label.MessagesTotal
+        }
+        
+        if label.Type == "system" {
+            systemLabels = append(systemLabels, labelInfo)
+        } else if label.Type == "user" {
+            userLabels = append(userLabels, labelInfo)
+        }
+    }
+
+    gmailLabelFields.Select(systemLabels)
+    gmailLabelFields.Select(userLabels)
+
+    result := map[string]interface{}{
+        "count": len(labels.Labels),
+        "systemLabels": systemLabels,
+        "userLabels": userLabels,
+    }
+
+    yamlResult, err := yaml.Marshal(result)
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("failed to marshal labels: %v", err)), nil
+    }
+
+    return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gmailVacationHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	action, _ := arguments["action"].(string)
+
+	switch action {
+	case "get":
+		return gmailGetVacationHandler(arguments)
+	case "set":
+		return gmailSetVacationHandler(arguments)
+	case "disable":
+		return gmailDisableVacationHandler(arguments)
+	default:
+		return mcp.NewToolResultError("Invalid action. Must be one of: get, set, disable"), nil
+	}
+}
+
+func gmailGetVacationHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+
+	vacation, err := gmailService(profile).Users.Settings.GetVacation("me").Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vacation settings: %v", err)), nil
+	}
+
+	yamlResult, err := yaml.Marshal(vacationSummary(vacation))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal vacation settings: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gmailSetVacationHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	subject, _ := arguments["subject"].(string)
+	message, _ := arguments["message"].(string)
+	startStr, _ := arguments["start"].(string)
+	endStr, _ := arguments["end"].(string)
+	restrictToContacts, _ := arguments["restrict_to_contacts"].(bool)
+	restrictToDomain, _ := arguments["restrict_to_domain"].(bool)
+
+	if strings.TrimSpace(message) == "" {
+		return mcp.NewToolResultError("message is required for set action"), nil
+	}
+
+	now := time.Now()
+	vacation := &gmail.VacationSettings{
+		EnableAutoReply:       true,
+		ResponseSubject:       subject,
+		ResponseBodyPlainText: message,
+		RestrictToContacts:    restrictToContacts,
+		RestrictToDomain:      restrictToDomain,
+	}
+
+	var start, end time.Time
+	var errs []string
+	if startStr != "" {
+		t, err := util.ParseRelativeTime(startStr, now)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid start: %v", err))
+		}
+		start = t
+	}
+	if endStr != "" {
+		t, err := util.ParseRelativeTime(endStr, now)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid end: %v", err))
+		}
+		end = t
+	}
+	if len(errs) == 0 && !end.IsZero() {
+		if !start.IsZero() && !end.After(start) {
+			errs = append(errs, fmt.Sprintf("end %s is not after start %s", end.Format("2006-01-02 15:04"), start.Format("2006-01-02 15:04")))
+		}
+		if end.Before(now) {
+			errs = append(errs, fmt.Sprintf("end %s is in the past", end.Format("2006-01-02 15:04")))
+		}
+	}
+	if len(errs) > 0 {
+		return mcp.NewToolResultError(strings.Join(errs, "; ")), nil
+	}
+
+	if !start.IsZero() {
+		vacation.StartTime = start.UnixMilli()
+	}
+	if !end.IsZero() {
+		vacation.EndTime = end.UnixMilli()
+	}
+
+	updated, err := gmailService(profile).Users.Settings.UpdateVacation("me", vacation).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update vacation settings: %v", err)), nil
+	}
+
+	yamlResult, err := yaml.Marshal(vacationSummary(updated))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal vacation settings: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gmailDisableVacationHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+
+	_, err := gmailService(profile).Users.Settings.UpdateVacation("me", &gmail.VacationSettings{
+		EnableAutoReply: false,
+		ForceSendFields: []string{"EnableAutoReply"},
+	}).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to disable vacation responder: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText("Vacation responder disabled"), nil
+}
+
+func gmailSettingsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	action, _ := arguments["action"].(string)
+	setting, _ := arguments["setting"].(string)
+
+	if action != "get" && action != "set" {
+		return mcp.NewToolResultError("Invalid action. Must be one of: get, set"), nil
+	}
+
+	var result interface{}
+	var err error
+	switch setting {
+	case "forwarding":
+		if action == "get" {
+			result, err = gmailService(profile).Users.Settings.GetAutoForwarding("me").Do()
+		} else {
+			result, err = updateAutoForwarding(profile, arguments)
+		}
+	case "imap":
+		if action == "get" {
+			result, err = gmailService(profile).Users.Settings.GetImap("me").Do()
+		} else {
+			result, err = updateImap(profile, arguments)
+		}
+	case "pop":
+		if action == "get" {
+			result, err = gmailService(profile).Users.Settings.GetPop("me").Do()
+		} else {
+			result, err = updatePop(profile, arguments)
+		}
+	default:
+		return mcp.NewToolResultError("Invalid setting. Must be one of: forwarding, imap, pop"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to %s %s settings: %v", action, setting, err)), nil
+	}
+
+	yamlResult, err := yaml.Marshal(apiObjectMap(result))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal settings: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gmailForwardingHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	action, _ := arguments["action"].(string)
+	address, _ := arguments["forwarding_address"].(string)
+
+	var result interface{}
+	var err error
+	switch action {
+	case "list":
+		var resp *gmail.ListForwardingAddressesResponse
+		resp, err = gmailService(profile).Users.Settings.ForwardingAddresses.List("me").Do()
+		if err == nil {
+			addresses := make([]map[string]string, 0, len(resp.ForwardingAddresses))
+			for _, forwarding := range resp.ForwardingAddresses {
+				addresses = append(addresses, map[string]string{
+					"forwardingEmail":    forwarding.ForwardingEmail,
+					"verificationStatus": forwarding.VerificationStatus,
+				})
+			}
+			result = map[string]interface{}{
+				"count":               len(addresses),
+				"forwardingAddresses": addresses,
+			}
+		}
+	case "get":
+		if address == "" {
+			return mcp.NewToolResultError("forwarding_address is required for get action"), nil
+		}
+		result, err = gmailService(profile).Users.Settings.ForwardingAddresses.Get("me", address).Do()
+	case "get_auto_forwarding":
+		result, err = gmailService(profile).Users.Settings.GetAutoForwarding("me").Do()
+	case "update_auto_forwarding":
+		result, err = updateAutoForwarding(profile, arguments)
+	default:
+		return mcp.NewToolResultError("Invalid action. Must be one of: list, get, get_auto_forwarding, update_auto_forwarding"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to %s forwarding: %v", strings.ReplaceAll(action, "_", " "), err)), nil
+	}
+
+	yamlResult, err := yaml.Marshal(apiObjectMap(result))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal forwarding settings: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// apiObjectMap converts a Google API response object into a plain map using
+// its JSON field names, dropping the embedded HTTP response metadata that
+// would otherwise appear in YAML output. Other values are returned unchanged.
+func apiObjectMap(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return value
+	}
+	return object
+}
+
+// updateAutoForwarding merges the given arguments into the current
+// auto-forwarding settings. Enabling forwarding requires an address whose
+// verification has been accepted, since Gmail rejects unverified addresses.
+func updateAutoForwarding(profile string, arguments map[string]interface{}) (*gmail.AutoForwarding, error) {
+	current, err := gmailService(profile).Users.Settings.GetAutoForwarding("me").Do()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled, ok := arguments["enabled"].(bool); ok {
+		current.Enabled = enabled
+	}
+	if address, _ := arguments["forwarding_address"].(string); address != "" {
+		current.EmailAddress = address
+	}
+	if disposition, _ := arguments["disposition"].(string); disposition != "" {
+		switch disposition {
+		case "leaveInInbox", "archive", "trash", "markRead":
+			current.Disposition = disposition
+		default:
+			return nil, fmt.Errorf("invalid disposition. Must be one of: leaveInInbox, archive, trash, markRead")
+		}
+	}
+
+	if current.Enabled {
+		if current.EmailAddress == "" {
+			return nil, fmt.Errorf("forwarding_address is required to enable auto-forwarding")
+		}
+		address, err := gmailService(profile).Users.Settings.ForwardingAddresses.Get("me", current.EmailAddress).Do()
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a registered forwarding address: %v", current.EmailAddress, err)
+		}
+		if address.VerificationStatus != "accepted" {
+			return nil, fmt.Errorf("forwarding address %s is not verified (status: %s)", current.EmailAddress, address.VerificationStatus)
+		}
+		if current.Disposition == "" {
+			current.Disposition = "leaveInInbox"
+		}
+	}
+
+	current.ForceSendFields = []string{"Enabled"}
+	return gmailService(profile).Users.Settings.UpdateAutoForwarding("me", current).Do()
+}
+
+// updateImap merges the given arguments into the current IMAP settings.
+func updateImap(profile string, arguments map[string]interface{}) (*gmail.ImapSettings, error) {
+	current, err := gmailService(profile).Users.Settings.GetImap("me").Do()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled, ok := arguments["enabled"].(bool); ok {
+		current.Enabled = enabled
+	}
+	if autoExpunge, ok := arguments["auto_expunge"].(bool); ok {
+		current.AutoExpunge = autoExpunge
+	}
+	if behavior, _ := arguments["expunge_behavior"].(string); behavior != "" {
+		switch behavior {
+		case "archive", "trash", "deleteForever":
+			current.ExpungeBehavior = behavior
+		default:
+			return nil, fmt.Errorf("invalid expunge_behavior. Must be one of: archive, trash, deleteForever")
+		}
+	}
+	if maxFolderSize, ok := arguments["max_folder_size"].(float64); ok {
+		if maxFolderSize < 0 {
+			return nil, fmt.Errorf("max_folder_size must not be negative")
+		}
+		current.MaxFolderSize = int64(maxFolderSize)
+	}
+
+	current.ForceSendFields = []string{"Enabled", "AutoExpunge", "MaxFolderSize"}
+	return gmailService(profile).Users.Settings.UpdateImap("me", current).Do()
+}
+
+// updatePop merges the given arguments into the current POP settings.
+func updatePop(profile string, arguments map[string]interface{}) (*gmail.PopSettings, error) {
+	current, err := gmailService(profile).Users.Settings.GetPop("me").Do()
+	if err != nil {
+		return nil, err
+	}
+
+	if window, _ := arguments["access_window"].(string); window != "" {
+		switch window {
+		case "disabled", "allMail", "fromNowOn":
+			current.AccessWindow = window
+		default:
+			return nil, fmt.Errorf("invalid access_window. Must be one of: disabled, allMail, fromNowOn")
+		}
+	}
+	if disposition, _ := arguments["disposition"].(string); disposition != "" {
+		switch disposition {
+		case "leaveInInbox", "archive", "trash", "markRead":
+			current.Disposition = disposition
+		default:
+			return nil, fmt.Errorf("invalid disposition. Must be one of: leaveInInbox, archive, trash, markRead")
+		}
+	}
+
+	return gmailService(profile).Users.Settings.UpdatePop("me", current).Do()
+}
+
+// vacationSummary converts vacation settings into tool output, rendering the
+// millisecond timestamps as readable times.
+func vacationSummary(vacation *gmail.VacationSettings) map[string]interface{} {
+	summary := map[string]interface{}{
+		"enabled":            vacation.EnableAutoReply,
+		"subject":            vacation.ResponseSubject,
+		"message":            vacation.ResponseBodyPlainText,
+		"restrictToContacts": vacation.RestrictToContacts,
+		"restrictToDomain":   vacation.RestrictToDomain,
+	}
+	if vacation.ResponseBodyPlainText == "" && vacation.ResponseBodyHtml != "" {
+		summary["message"] = util.HTMLToText(vacation.ResponseBodyHtml)
+	}
+	if vacation.StartTime > 0 {
+		summary["start"] = time.UnixMilli(vacation.StartTime).In(util.DefaultLocation()).Format("2006-01-02 15:04")
+	}
+	if vacation.EndTime > 0 {
+		summary["end"] = time.UnixMilli(vacation.EndTime).In(util.DefaultLocation()).Format("2006-01-02 15:04")
+	}
+	return summary
+}
+
+func gmailLabelCountsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	labelsStr, _ := arguments["labels"].(string)
+
+	wanted := make(map[string]bool)
+	for _, label := range strings.Split(labelsStr, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			wanted[strings.ToLower(label)] = true
+		}
+	}
+
+	labels, err := gmailService(profile).Users.Labels.List("me").Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
+	}
+
+	selected := make([]*gmail.Label, 0, len(labels.Labels))
+	found := make(map[string]bool)
+	for _, label := range labels.Labels {
+		id, name := strings.ToLower(label.Id), strings.ToLower(label.Name)
+		if len(wanted) > 0 && !wanted[id] && !wanted[name] {
+			continue
+		}
+		found[id], found[name] = true, true
+		selected = append(selected, label)
+	}
+
+	details, err := labelDetails(profile, selected)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	counts := make([]map[string]interface{}, 0, len(details))
+	for _, detail := range details {
+		counts = append(counts, map[string]interface{}{
+			"id":             detail.Id,
+			"name":           detail.Name,
+			"messagesTotal":  detail.MessagesTotal,
+			"messagesUnread": detail.MessagesUnread,
+			"threadsTotal":   detail.ThreadsTotal,
+			"threadsUnread":  detail.ThreadsUnread,
+		})
+	}
+
+	result := map[string]interface{}{
+		"count":  len(counts),
+		"labels": counts,
+	}
 
-    // If not found, create new label
-    newLabel := &gmail.Label{
-        Name:                  name,
-        MessageListVisibility: "show",
-        LabelListVisibility:   "labelShow",
-    }
+	notFound := make([]string, 0)
+	for label := range wanted {
+		if !found[label] {
+			notFound = append(notFound, label)
+		}
+	}
+	if len(notFound) > 0 {
+		sort.Strings(notFound)
+		result["notFound"] = notFound
+	}
 
-    label, err := gmailService().Users.Labels.Create("me", newLabel).Do()
-    if err != nil {
-        return nil, fmt.Errorf("failed to create label: %v", err)
-    }
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal label counts: %v", err)), nil
+	}
 
-    return label, nil
+	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-func gmailListFiltersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-    filters, err := gmailService().Users.Settings.Filters.List("me").Do()
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("failed to list filters: %v", err)), nil
-    }
+func gmailUnreadSummaryHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	includeEmpty, _ := arguments["include_empty"].(bool)
 
-    filtersResult := make([]map[string]interface{}, 0)
-    
-    for _, filter := range filters.Filter {
-        filterInfo := map[string]interface{}{
-            "id": filter.Id,
-            "criteria": map[string]string{},
-            "actions": map[string]interface{}{},
-        }
-        
-        // Add criteria
-        if filter.Criteria.From != "" {
-            filterInfo["criteria"].(map[string]string)["from"] = filter.Criteria.From
-        }
-        if filter.Criteria.To != "" {
-            filterInfo["criteria"].(map[string]string)["to"] = filter.Criteria.To
-        }
-        if filter.Criteria.Subject != "" {
-            filterInfo["criteria"].(map[string]string)["subject"] = filter.Criteria.Subject
-        }
-        if filter.Criteria.Query != "" {
-            filterInfo["criteria"].(map[string]string)["query"] = filter.Criteria.Query
-        }
+	labels, err := gmailService(profile).Users.Labels.List("me").Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
+	}
 
-        // Add actions
-        if len(filter.Action.AddLabelIds) > 0 {
-            filterInfo["actions"].(map[string]interface{})["addLabels"] = filter.Action.AddLabelIds
-        }
-        if len(filter.Action.RemoveLabelIds) > 0 {
-            filterInfo["actions"].(map[string]interface{})["removeLabels"] = filter.Action.RemoveLabelIds
-        }
-        
-        filtersResult = append(filtersResult, filterInfo)
-    }
+	details, err := labelDetails(profile, labels.Labels)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-    result := map[string]interface{}{
-        "count": len(filtersResult),
-        "filters": filtersResult,
-    }
+	sort.SliceStable(details, func(i, j int) bool {
+		if details[i].MessagesUnread != details[j].MessagesUnread {
+			return details[i].MessagesUnread > details[j].MessagesUnread
+		}
+		return details[i].Name < details[j].Name
+	})
+
+	summary := make([]map[string]interface{}, 0, len(details))
+	var inboxUnread int64
+	for _, detail := range details {
+		if detail.Id == "INBOX" {
+			inboxUnread = detail.MessagesUnread
+		}
+		if detail.MessagesUnread == 0 && !includeEmpty {
+			continue
+		}
+		summary = append(summary, map[string]interface{}{
+			"id":             detail.Id,
+			"name":           detail.Name,
+			"type":           detail.Type,
+			"messagesUnread": detail.MessagesUnread,
+			"messagesTotal":  detail.MessagesTotal,
+		})
+	}
 
-    yamlResult, err := yaml.Marshal(result)
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("failed to marshal filters: %v", err)), nil
-    }
+	// A message carries several labels, so per-label unread counts overlap
+	// and are not summed
+	result := map[string]interface{}{
+		"inboxUnread": inboxUnread,
+		"count":       len(summary),
+		"labels":      summary,
+	}
 
-    return mcp.NewToolResultText(string(yamlResult)), nil
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal unread summary: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
-func gmailLabelHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	action, _ := arguments["action"].(string)
-	
-	switch action {
-	case "list":
-		return gmailListLabelsHandler(arguments)
-	case "delete":
-		return gmailDeleteLabelHandler(arguments)
+// inboxSummaryQuery selects the mail gmail_inbox_summary reports on.
+const inboxSummaryQuery = "in:inbox (is:unread OR is:important)"
+
+func gmailInboxSummaryHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	groupBy, _ := arguments["group_by"].(string)
+	switch groupBy {
+	case "":
+		groupBy = "sender"
+	case "sender", "sender_domain", "label":
 	default:
-		return mcp.NewToolResultError("Invalid action. Must be one of: list, delete"), nil
+		return mcp.NewToolResultError("Invalid group_by. Must be one of: sender, sender_domain, label"), nil
+	}
+	maxResults := 50
+	if value, ok := arguments["max_results"].(float64); ok && value > 0 {
+		maxResults = int(value)
+	}
+	if maxResults > 100 {
+		maxResults = 100
 	}
-}
 
-func gmailListLabelsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-    labels, err := gmailService().Users.Labels.List("me").Do()
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
-    }
+	resp, err := gmailService(profile).Users.Messages.List("me").Q(inboxSummaryQuery).MaxResults(int64(maxResults)).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search emails: %v", err)), nil
+	}
 
-    systemLabels := make([]map[string]interface{}, 0)
-    userLabels := make([]map[string]interface{}, 0)
+	fetched, errs := util.MapConcurrent(resp.Messages, maxFetchConcurrency, func(msg *gmail.Message) (*gmail.Message, error) {
+		return gmailService(profile).Users.Messages.Get("me", msg.Id).
+			Format("metadata").
+			MetadataHeaders("From", "Subject", "Date").
+			Do()
+	})
+
+	emails := make([]map[string]interface{}, 0, len(resp.Messages))
+	unread, important := 0, 0
+	for i, msg := range resp.Messages {
+		if errs[i] != nil {
+			log.Printf("Failed to get message %s: %v", msg.Id, errs[i])
+			continue
+		}
+		message := fetched[i]
+
+		emailInfo := map[string]interface{}{
+			"id":           msg.Id,
+			"threadId":     message.ThreadId,
+			"snippet":      message.Snippet,
+			"internalDate": message.InternalDate,
+			"labelIds":     message.LabelIds,
+		}
+		for _, header := range messageHeaders(message) {
+			switch header.Name {
+			case "From":
+				emailInfo["from"] = header.Value
+			case "Subject":
+				emailInfo["subject"] = header.Value
+			case "Date":
+				emailInfo["date"] = header.Value
+			}
+		}
+		if slices.Contains(message.LabelIds, "UNREAD") {
+			emailInfo["unread"] = true
+			unread++
+		}
+		if slices.Contains(message.LabelIds, "IMPORTANT") {
+			emailInfo["important"] = true
+			important++
+		}
+
+		emails = append(emails, emailInfo)
+	}
 
-    for _, label := range labels.Labels {
-        labelInfo := map[string]interface{}{
-            "id": label.Id,
-            "name": label.Name,
-        }
-        
-        if label.MessagesTotal > 0 {
-            labelInfo["messagesTotal"] = label.MessagesTotal
-        }
-        
-        if label.Type == "system" {
-            systemLabels = append(systemLabels, labelInfo)
-        } else if label.Type == "user" {
-            userLabels = append(userLabels, labelInfo)
-        }
-    }
+	util.SanitizeValue(emails)
 
-    result := map[string]interface{}{
-        "count": len(labels.Labels),
-        "systemLabels": systemLabels,
-        "userLabels": userLabels,
-    }
+	groups, err := groupEmails(profile, emails, groupBy)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-    yamlResult, err := yaml.Marshal(result)
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("failed to marshal labels: %v", err)), nil
-    }
+	result := map[string]interface{}{
+		"query":     inboxSummaryQuery,
+		"count":     len(emails),
+		"unread":    unread,
+		"important": important,
+		"groupBy":   groupBy,
+		"groups":    groups,
+	}
+	if resp.NextPageToken != "" {
+		result["truncated"] = true
+		result["resultSizeEstimate"] = resp.ResultSizeEstimate
+	}
 
-    return mcp.NewToolResultText(string(yamlResult)), nil
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal summary: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// labelDetails fetches each label individually, at most maxFetchConcurrency
+// at a time, since Labels.List omits the message and thread counts.
+func labelDetails(profile string, labels []*gmail.Label) ([]*gmail.Label, error) {
+	details, errs := util.MapConcurrent(labels, maxFetchConcurrency, func(label *gmail.Label) (*gmail.Label, error) {
+		return gmailService(profile).Users.Labels.Get("me", label.Id).Do()
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get label %s: %v", labels[i].Name, err)
+		}
+	}
+	return details, nil
 }
 
 func gmailDeleteFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
     filterID, ok := arguments["filter_id"].(string)
     if !ok {
         return mcp.NewToolResultError("filter_id must be a string"), nil
@@ -403,7 +2121,12 @@ func gmailDeleteFilterHandler(arguments map[string]interface{}) (*mcp.CallToolRe
         return mcp.NewToolResultError("filter_id cannot be empty"), nil
     }
 
-    err := gmailService().Users.Settings.Filters.Delete("me", filterID).Do()
+    impact := fmt.Sprintf("Permanently deletes Gmail filter %s. Messages it already processed are not changed.", filterID)
+    if confirm := requireConfirmation(arguments, "gmail_filter.delete:"+profile+":"+filterID, impact); confirm != nil {
+        return confirm, nil
+    }
+
+    err := gmailService(profile).Users.Settings.Filters.Delete("me", filterID).Do()
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to delete filter: %v", err)), nil
     }
@@ -412,6 +2135,7 @@ func gmailDeleteFilterHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 }
 
 func gmailDeleteLabelHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	labelID, ok := arguments["label_id"].(string)
 	if !ok {
 		return mcp.NewToolResultError("label_id must be a string"), nil
@@ -421,7 +2145,12 @@ func gmailDeleteLabelHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 		return mcp.NewToolResultError("label_id cannot be empty"), nil
 	}
 
-	err := gmailService().Users.Labels.Delete("me", labelID).Do()
+	impact := fmt.Sprintf("Permanently deletes Gmail label %s and removes it from every message that has it. The messages themselves are kept.", labelID)
+	if confirm := requireConfirmation(arguments, "gmail_label.delete:"+profile+":"+labelID, impact); confirm != nil {
+		return confirm, nil
+	}
+
+	err := gmailService(profile).Users.Labels.Delete("me", labelID).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to delete label: %v", err)), nil
 	}
@@ -430,15 +2159,17 @@ func gmailDeleteLabelHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 }
 
 func gmailReadEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
     messageID, ok := arguments["message_id"].(string)
     if !ok {
         return mcp.NewToolResultError("message_id must be a string"), nil
     }
 
     includeAttachments, _ := arguments["include_attachments"].(bool)
+    includeThreadContext, _ := arguments["include_thread_context"].(bool)
 
     // Get the full email message
-    message, err := gmailService().Users.Messages.Get("me", messageID).Format("full").Do()
+    message, err := gmailService(profile).Users.Messages.Get("me", messageID).Format("full").Do()
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to get email: %v", err)), nil
     }
@@ -449,6 +2180,24 @@ func gmailReadEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResul
         "body": "",
     }
 
+    if includeThreadContext {
+        threadContext, err := threadContextFor(profile, message)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        emailResult["threadContext"] = threadContext
+    }
+
+    if message.Payload == nil {
+        emailResult["body"] = "Message has no payload (it may be a draft or malformed)"
+        emailResult["snippet"] = message.Snippet
+        yamlResult, err := yaml.Marshal(util.SanitizeValue(emailResult))
+        if err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("failed to marshal email: %v", err)), nil
+        }
+        return mcp.NewToolResultText(string(yamlResult)), nil
+    }
+
     // Extract headers
     for _, header := range message.Payload.Headers {
         switch header.Name {
@@ -467,7 +2216,10 @@ func gmailReadEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResul
             if part.Filename != "" {
                 attachmentInfo := map[string]interface{}{
                     "filename": part.Filename,
-                    "size": part.Body.Size,
+                }
+                if part.Body != nil {
+                    attachmentInfo["size"] = part.Body.Size
+                    attachmentInfo["attachmentId"] = part.Body.AttachmentId
                 }
                 attachments = append(attachments, attachmentInfo)
             }
@@ -478,7 +2230,7 @@ func gmailReadEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResul
         }
     }
 
-    yamlResult, err := yaml.Marshal(emailResult)
+    yamlResult, err := yaml.Marshal(util.SanitizeValue(emailResult))
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to marshal email: %v", err)), nil
     }
@@ -487,30 +2239,111 @@ func gmailReadEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 }
 
 func extractMessageBody(payload *gmail.MessagePart) string {
-    if payload.MimeType == "text/plain" && payload.Body.Data != "" {
-        data, err := base64.URLEncoding.DecodeString(payload.Body.Data)
-        if err != nil {
-            return fmt.Sprintf("Error decoding body: %v", err)
-        }
-        return string(data)
-    }
+	if payload == nil {
+		return "No readable text body found"
+	}
 
-    if payload.Parts != nil {
-        for _, part := range payload.Parts {
-            if part.MimeType == "text/plain" {
-                data, err := base64.URLEncoding.DecodeString(part.Body.Data)
-                if err != nil {
-                    continue
-                }
-                return string(data)
-            }
-        }
-    }
+	if part := findBodyPart(payload, "text/plain"); part != nil {
+		data, err := util.DecodeGmailData(part.Body.Data)
+		if err != nil {
+			return fmt.Sprintf("Error decoding body: %v", err)
+		}
+		return string(data)
+	}
+
+	// HTML-only messages are converted to readable text
+	if part := findBodyPart(payload, "text/html"); part != nil {
+		data, err := util.DecodeGmailData(part.Body.Data)
+		if err != nil {
+			return fmt.Sprintf("Error decoding body: %v", err)
+		}
+		return util.HTMLToText(string(data))
+	}
+
+	return "No readable text body found"
+}
+
+// findBodyPart returns the first part of the given MIME type with body data,
+// searching nested multipart parts depth-first.
+func findBodyPart(part *gmail.MessagePart, mimeType string) *gmail.MessagePart {
+	if part == nil {
+		return nil
+	}
+	if part.MimeType == mimeType && part.Body != nil && part.Body.Data != "" {
+		return part
+	}
+	for _, child := range part.Parts {
+		if found := findBodyPart(child, mimeType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// quoteMessage formats an original message body as a quoted block for a reply.
+func quoteMessage(message *gmail.Message) string {
+	var sender, date string
+	for _, header := range messageHeaders(message) {
+		switch header.Name {
+		case "From":
+			sender = header.Value
+		case "Date":
+			date = header.Value
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(extractMessageBody(message.Payload), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+
+	return fmt.Sprintf("On %s, %s wrote:\r\n%s", date, sender, strings.Join(lines, "\r\n"))
+}
+
+func gmailDownloadAttachmentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	messageID, _ := arguments["message_id"].(string)
+	attachmentID, _ := arguments["attachment_id"].(string)
+	outputPath, _ := arguments["output_path"].(string)
+	if messageID == "" || attachmentID == "" || outputPath == "" {
+		return mcp.NewToolResultError("message_id, attachment_id and output_path are required"), nil
+	}
+	overwrite, _ := arguments["overwrite"].(bool)
+
+	// Gmail returns attachment data inline rather than as a ranged stream, so
+	// a failed fetch is retried in full
+	body, err := util.Retry(func() (*gmail.MessagePartBody, error) {
+		return gmailService(profile).Users.Messages.Attachments.Get("me", messageID, attachmentID).Do()
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get attachment: %v", err)), nil
+	}
+
+	data, err := util.DecodeGmailData(body.Data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to decode attachment: %v", err)), nil
+	}
+
+	if err := util.WriteFileAtomic(outputPath, data, overwrite); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save attachment: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"messageId": messageID,
+		"path":      outputPath,
+		"size":      len(data),
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
 
-    return "No readable text body found"
+	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
 func gmailReplyEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
     messageID, ok := arguments["message_id"].(string)
     if !ok {
         return mcp.NewToolResultError("message_id must be a string"), nil
@@ -522,16 +2355,21 @@ func gmailReplyEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResu
     }
 
     replyAll, _ := arguments["reply_all"].(bool)
+    includeQuote, _ := arguments["include_quote"].(bool)
 
-    // Get the original message to extract headers
-    originalMessage, err := gmailService().Users.Messages.Get("me", messageID).Format("metadata").Do()
+    // Get the original message to extract headers, and the body when quoting
+    format := "metadata"
+    if includeQuote {
+        format = "full"
+    }
+    originalMessage, err := gmailService(profile).Users.Messages.Get("me", messageID).Format(format).Do()
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to get original email: %v", err)), nil
     }
 
     // Extract necessary headers
     var from, to, subject, references, messageIDHeader string
-    for _, header := range originalMessage.Payload.Headers {
+    for _, header := range messageHeaders(originalMessage) {
         switch header.Name {
         case "From":
             to = header.Value // Original sender becomes recipient
@@ -556,13 +2394,13 @@ func gmailReplyEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResu
     // Prepare recipients
     recipients := []string{to}
     if replyAll {
-        // Add original To recipients (excluding ourselves)
-        originalRecipients := strings.Split(from, ",")
-        for _, recipient := range originalRecipients {
-            recipient = strings.TrimSpace(recipient)
-            if recipient != "" && !strings.Contains(recipient, "me@") {
-                recipients = append(recipients, recipient)
-            }
+        // Add original To recipients, excluding ourselves and duplicates
+        self, err := authenticatedEmail(profile)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        if all := replyAllRecipients(to, from, self); len(all) > 0 {
+            recipients = all
         }
     }
 
@@ -572,6 +2410,8 @@ func gmailReplyEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResu
     headers["Subject"] = subject
     headers["References"] = references
     headers["In-Reply-To"] = messageIDHeader
+    headers["Date"] = time.Now().Format(time.RFC1123Z)
+    headers["Message-ID"] = newMessageID(profile)
 
     // Construct the raw message
     var rawMessage strings.Builder
@@ -580,15 +2420,321 @@ func gmailReplyEmailHandler(arguments map[string]interface{}) (*mcp.CallToolResu
     }
     rawMessage.WriteString("\r\n")
     rawMessage.WriteString(replyText)
+    if includeQuote {
+        rawMessage.WriteString("\r\n\r\n")
+        rawMessage.WriteString(quoteMessage(originalMessage))
+    }
 
     // Encode the raw message
     message.Raw = base64.URLEncoding.EncodeToString([]byte(rawMessage.String()))
 
     // Send the reply
-    _, err = gmailService().Users.Messages.Send("me", &message).Do()
+    _, err = gmailService(profile).Users.Messages.Send("me", &message).Do()
     if err != nil {
         return mcp.NewToolResultError(fmt.Sprintf("failed to send reply: %v", err)), nil
     }
 
     return mcp.NewToolResultText("Reply sent successfully"), nil
-}
\ No newline at end of file
+}
+
+// newMessageID generates a unique RFC 5322 Message-ID for an outgoing message,
+// using the sending account's domain so receiving servers thread it correctly.
+func newMessageID(profile string) string {
+	domain := "mail.gmail.com"
+	if email, err := authenticatedEmail(profile); err == nil {
+		if at := strings.LastIndex(email, "@"); at >= 0 && at < len(email)-1 {
+			domain = email[at+1:]
+		}
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Sprintf("<%d@%s>", time.Now().UnixNano(), domain)
+	}
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// groupEmails buckets search results by sender domain, label name, or the
+// day/week the message was received. Groups are ordered by size, largest first.
+func groupEmails(profile string, emails []map[string]interface{}, groupBy string) ([]map[string]interface{}, error) {
+	labelNames := map[string]string{}
+	if groupBy == "label" {
+		labels, err := gmailService(profile).Users.Labels.List("me").Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %v", err)
+		}
+		for _, label := range labels.Labels {
+			labelNames[label.Id] = label.Name
+		}
+	}
+
+	buckets := map[string][]map[string]interface{}{}
+	for _, email := range emails {
+		internalDate, _ := email["internalDate"].(int64)
+		labelIds, _ := email["labelIds"].([]string)
+		delete(email, "internalDate")
+		delete(email, "labelIds")
+
+		var keys []string
+		switch groupBy {
+		case "sender":
+			from, _ := email["from"].(string)
+			keys = []string{senderAddress(from)}
+		case "sender_domain":
+			from, _ := email["from"].(string)
+			keys = []string{senderDomain(from)}
+		case "label":
+			for _, id := range labelIds {
+				name := labelNames[id]
+				if name == "" {
+					name = id
+				}
+				keys = append(keys, name)
+			}
+			if len(keys) == 0 {
+				keys = []string{"(no label)"}
+			}
+		case "day":
+			keys = []string{time.UnixMilli(internalDate).In(util.DefaultLocation()).Format("2006-01-02")}
+		case "week":
+			year, week := time.UnixMilli(internalDate).In(util.DefaultLocation()).ISOWeek()
+			keys = []string{fmt.Sprintf("%d-W%02d", year, week)}
+		}
+
+		for _, key := range keys {
+			buckets[key] = append(buckets[key], email)
+		}
+	}
+
+	groups := make([]map[string]interface{}, 0, len(buckets))
+	for key, items := range buckets {
+		groups = append(groups, map[string]interface{}{
+			"key":    key,
+			"count":  len(items),
+			"emails": items,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		ci, cj := groups[i]["count"].(int), groups[j]["count"].(int)
+		if ci != cj {
+			return ci > cj
+		}
+		return groups[i]["key"].(string) < groups[j]["key"].(string)
+	})
+
+	return groups, nil
+}
+
+// senderAddress extracts the lower-cased address from a From header value.
+func senderAddress(from string) string {
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		return strings.ToLower(parsed.Address)
+	}
+	return strings.ToLower(strings.TrimSpace(from))
+}
+
+// senderDomain extracts the lower-cased domain from a From header value.
+func senderDomain(from string) string {
+	address := from
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		address = parsed.Address
+	}
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return "(unknown)"
+	}
+	return strings.ToLower(strings.Trim(address[at+1:], "> "))
+}
+
+func gmailInsertHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	rawFile, _ := arguments["raw_file"].(string)
+	rawContent, _ := arguments["raw_content"].(string)
+	labelIDsStr, _ := arguments["label_ids"].(string)
+	internalDateSource, _ := arguments["internal_date_source"].(string)
+
+	if rawFile == "" && rawContent == "" {
+		return mcp.NewToolResultError("either raw_file or raw_content is required"), nil
+	}
+	if rawFile != "" && rawContent != "" {
+		return mcp.NewToolResultError("provide only one of raw_file or raw_content"), nil
+	}
+
+	if internalDateSource == "" {
+		internalDateSource = "receivedTime"
+	}
+	if internalDateSource != "receivedTime" && internalDateSource != "dateHeader" {
+		return mcp.NewToolResultError("Invalid internal_date_source. Must be one of: receivedTime, dateHeader"), nil
+	}
+
+	raw := []byte(rawContent)
+	if rawFile != "" {
+		data, err := os.ReadFile(rawFile)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read raw_file: %v", err)), nil
+		}
+		raw = data
+	}
+
+	var labelIDs []string
+	for _, id := range strings.Split(labelIDsStr, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			labelIDs = append(labelIDs, id)
+		}
+	}
+
+	message := &gmail.Message{
+		Raw:      base64.URLEncoding.EncodeToString(raw),
+		LabelIds: labelIDs,
+	}
+
+	inserted, err := gmailService(profile).Users.Messages.Insert("me", message).
+		InternalDateSource(internalDateSource).
+		Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to insert message: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"id":       inserted.Id,
+		"threadId": inserted.ThreadId,
+		"labelIds": inserted.LabelIds,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+func gmailThreadInfoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	threadID, _ := arguments["thread_id"].(string)
+	if threadID == "" {
+		return mcp.NewToolResultError("thread_id is required"), nil
+	}
+
+	thread, err := gmailService(profile).Users.Threads.Get("me", threadID).
+		Format("metadata").
+		MetadataHeaders("From", "To", "Cc", "Subject").
+		Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get thread: %v", err)), nil
+	}
+
+	var subject string
+	var first, last int64
+	unreadCount := 0
+
+	for _, message := range thread.Messages {
+		if first == 0 || message.InternalDate < first {
+			first = message.InternalDate
+		}
+		if message.InternalDate > last {
+			last = message.InternalDate
+		}
+		for _, labelID := range message.LabelIds {
+			if labelID == "UNREAD" {
+				unreadCount++
+				break
+			}
+		}
+
+		for _, header := range messageHeaders(message) {
+			if header.Name == "Subject" && subject == "" {
+				subject = header.Value
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"id":            thread.Id,
+		"subject":       subject,
+		"message_count": len(thread.Messages),
+		"participants":  threadParticipants(thread),
+		"has_unread":    unreadCount > 0,
+		"unread_count":  unreadCount,
+	}
+	if first != 0 {
+		result["first_message"] = time.UnixMilli(first).In(util.DefaultLocation()).Format("2006-01-02 15:04")
+		result["last_message"] = time.UnixMilli(last).In(util.DefaultLocation()).Format("2006-01-02 15:04")
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal thread info: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// replyAllRecipients combines the original sender with the original To
+// recipients, comparing addresses case-insensitively to drop duplicates and
+// the authenticated user.
+func replyAllRecipients(sender string, originalTo string, self string) []string {
+	seen := map[string]bool{strings.ToLower(self): true}
+	recipients := make([]string, 0)
+	for _, address := range append(parseAddressList(sender), parseAddressList(originalTo)...) {
+		key := strings.ToLower(strings.TrimSpace(address.Address))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if address.Name == "" {
+			recipients = append(recipients, address.Address)
+		} else {
+			recipients = append(recipients, address.String())
+		}
+	}
+	return recipients
+}
+
+// threadParticipants returns the distinct From/To/Cc addresses across a
+// thread's messages, in order of first appearance.
+func threadParticipants(thread *gmail.Thread) []string {
+	participants := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, message := range thread.Messages {
+		for _, header := range messageHeaders(message) {
+			switch header.Name {
+			case "From", "To", "Cc":
+				for _, participant := range parseAddressList(header.Value) {
+					key := strings.ToLower(participant.Address)
+					if !seen[key] {
+						seen[key] = true
+						participants = append(participants, participant.String())
+					}
+				}
+			}
+		}
+	}
+	return participants
+}
+
+// parseAddressList parses a comma-separated address header, falling back to
+// treating each comma-separated entry as a bare address when parsing fails.
+func parseAddressList(value string) []*mail.Address {
+	if addresses, err := mail.ParseAddressList(value); err == nil {
+		return addresses
+	}
+
+	addresses := make([]*mail.Address, 0)
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			addresses = append(addresses, &mail.Address{Address: part})
+		}
+	}
+	return addresses
+}
+
+// messageHeaders returns the top-level headers of a message, or nil when the
+// message has no payload (as happens for some drafts and malformed messages).
+func messageHeaders(message *gmail.Message) []*gmail.MessagePartHeader {
+	if message == nil || message.Payload == nil {
+		return nil
+	}
+	return message.Payload.Headers
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/nguyenvanduocit/google-mcp/services"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+// testProfile is the credential profile the fake Gmail server is bound to.
+const testProfile = "test"
+
+// fakeGmail serves the Gmail API from mux and points the Gmail service of
+// testProfile at it for the duration of the test.
+func fakeGmail(t *testing.T, mux *http.ServeMux) {
+	t.Helper()
+
+	dir := t.TempDir()
+	credentials := `{"installed":{"client_id":"test-client","client_secret":"test-secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`
+	token := `{"access_token":"test-token","token_type":"Bearer","expiry":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`
+	for name, content := range map[string]string{
+		testProfile + ".credentials.json": credentials,
+		testProfile + ".token.json":       token,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("GOOGLE_PROFILES_DIR", dir)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	original := gmailServices
+	gmailServices = services.NewProfileCache(func(client *http.Client) (*gmail.Service, error) {
+		return gmail.NewService(context.Background(), option.WithHTTPClient(client), option.WithEndpoint(server.URL+"/"))
+	})
+	t.Cleanup(func() { gmailServices = original })
+}
+
+// resultText returns the text of a tool result, failing the test when the
+// result is an error.
+func resultText(t *testing.T, result *mcp.CallToolResult, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result == nil || len(result.Content) == 0 {
+		t.Fatal("handler returned no content")
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("handler returned %T, want text content", result.Content[0])
+	}
+	if result.IsError {
+		t.Fatalf("handler returned an error result: %s", text.Text)
+	}
+	return text.Text
+}
+
+func TestGmailHandlersToleratePartialMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantRead []string
+	}{
+		{
+			name:     "nil payload",
+			message:  `{"id":"m1","threadId":"t1","snippet":"payload-less\u0007 snippet"}`,
+			wantRead: []string{"id: m1", "Message has no payload", "payload-less snippet"},
+		},
+		{
+			name:     "nil body on a single-part payload",
+			message:  `{"id":"m1","threadId":"t1","snippet":"bodiless","payload":{"mimeType":"text/plain","headers":[{"name":"From","value":"alice@example.com"},{"name":"Subject","value":"No body"}]}}`,
+			wantRead: []string{"id: m1", "Subject: No body", "No readable text body found"},
+		},
+		{
+			name:     "nil body on every part",
+			message:  `{"id":"m1","threadId":"t1","snippet":"bodiless parts","payload":{"mimeType":"multipart/mixed","headers":[{"name":"From","value":"alice@example.com"},{"name":"Subject","value":"Parts without bodies"}],"parts":[{"mimeType":"multipart/alternative","parts":[{"mimeType":"text/plain"},{"mimeType":"text/html"}]},{"mimeType":"application/pdf","filename":"report.pdf"}]}}`,
+			wantRead: []string{"id: m1", "Subject: Parts without bodies", "No readable text body found", "filename: report.pdf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /gmail/v1/users/me/messages", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"messages":[{"id":"m1","threadId":"t1"}]}`))
+			})
+			mux.HandleFunc("GET /gmail/v1/users/me/messages/m1", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.message))
+			})
+			fakeGmail(t, mux)
+
+			result, err := gmailSearchHandler(map[string]interface{}{
+				"query":   "in:inbox",
+				"profile": testProfile,
+			})
+			search := resultText(t, result, err)
+			for _, want := range []string{"count: 1", "id: m1"} {
+				if !strings.Contains(search, want) {
+					t.Errorf("search result missing %q:\n%s", want, search)
+				}
+			}
+
+			result, err = gmailReadEmailHandler(map[string]interface{}{
+				"message_id":          "m1",
+				"include_attachments": true,
+				"profile":             testProfile,
+			})
+			read := resultText(t, result, err)
+			for _, want := range tt.wantRead {
+				if !strings.Contains(read, want) {
+					t.Errorf("read result missing %q:\n%s", want, read)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageHelpersHandleNilParts(t *testing.T) {
+	if headers := messageHeaders(nil); headers != nil {
+		t.Errorf("messageHeaders(nil) = %v, want nil", headers)
+	}
+	if headers := messageHeaders(&gmail.Message{}); headers != nil {
+		t.Errorf("messageHeaders(no payload) = %v, want nil", headers)
+	}
+	if body := extractMessageBody(nil); body != "No readable text body found" {
+		t.Errorf("extractMessageBody(nil) = %q", body)
+	}
+	if body := extractMessageBody(&gmail.MessagePart{MimeType: "text/plain"}); body != "No readable text body found" {
+		t.Errorf("extractMessageBody(nil body) = %q", body)
+	}
+}
+
+func TestGmailReplyHeaders(t *testing.T) {
+	var sent []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gmail/v1/users/me/messages/m1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"m1","threadId":"t1","payload":{"headers":[{"name":"From","value":"alice@example.org"},{"name":"To","value":"me@example.com"},{"name":"Subject","value":"Lunch"},{"name":"Message-ID","value":"<original@example.org>"}]}}`))
+	})
+	mux.HandleFunc("GET /gmail/v1/users/me/profile", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"emailAddress":"me@example.com"}`))
+	})
+	mux.HandleFunc("POST /gmail/v1/users/me/messages/send", func(w http.ResponseWriter, r *http.Request) {
+		var message gmail.Message
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sent = append(sent, message.Raw)
+		w.Write([]byte(`{"id":"sent"}`))
+	})
+	fakeGmail(t, mux)
+	authenticatedEmails.Delete(testProfile)
+	t.Cleanup(func() { authenticatedEmails.Delete(testProfile) })
+
+	for range 2 {
+		result, err := gmailReplyEmailHandler(map[string]interface{}{
+			"message_id": "m1",
+			"reply_text": "Sounds good",
+			"profile":    testProfile,
+		})
+		resultText(t, result, err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(sent))
+	}
+
+	messageIDPattern := regexp.MustCompile(`^<[^<>@\s]+@[^<>@\s]+>$`)
+	seen := make(map[string]bool)
+	for _, raw := range sent {
+		decoded, err := base64.URLEncoding.DecodeString(raw)
+		if err != nil {
+			t.Fatalf("failed to decode raw message: %v", err)
+		}
+		msg, err := mail.ReadMessage(strings.NewReader(string(decoded)))
+		if err != nil {
+			t.Fatalf("failed to parse raw message: %v\n%s", err, decoded)
+		}
+
+		if _, err := msg.Header.Date(); err != nil {
+			t.Errorf("Date header %q is not parseable: %v", msg.Header.Get("Date"), err)
+		}
+
+		id := msg.Header.Get("Message-ID")
+		if !messageIDPattern.MatchString(id) {
+			t.Errorf("Message-ID %q is not of the form <id-left@id-right>", id)
+		}
+		if !strings.HasSuffix(id, "@example.com>") {
+			t.Errorf("Message-ID %q does not use the sender's domain", id)
+		}
+		if seen[id] {
+			t.Errorf("Message-ID %q was reused", id)
+		}
+		seen[id] = true
+
+		if got := msg.Header.Get("In-Reply-To"); got != "<original@example.org>" {
+			t.Errorf("In-Reply-To = %q, want %q", got, "<original@example.org>")
+		}
+		body, _ := io.ReadAll(msg.Body)
+		if string(body) != "Sounds good" {
+			t.Errorf("body = %q, want %q", body, "Sounds good")
+		}
+	}
+}
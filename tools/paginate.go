@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultMaxPages is how many pages auto_paginate follows when max_pages is not given.
+	defaultMaxPages = 5
+	// maxPagesLimit is the hard cap on max_pages, so a single call cannot walk
+	// an unbounded list.
+	maxPagesLimit = 20
+)
+
+// withAutoPaginate adds the optional "auto_paginate", "max_pages" and
+// "all_pages" arguments to list tools that follow page tokens.
+func withAutoPaginate() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithBoolean("auto_paginate", mcp.Description("Follow page tokens and aggregate results across pages (default: false)"))(tool)
+		mcp.WithNumber("max_pages", mcp.Description("Maximum number of pages to fetch when auto_paginate is set (default: 5, max: 20)"))(tool)
+		mcp.WithBoolean("all_pages", mcp.Description("Follow every page up to the server's cap of 20 pages; morePages reports whether results were truncated (default: false)"))(tool)
+	}
+}
+
+// maxPagesArg returns how many pages a list handler may fetch: maxPagesLimit
+// when all_pages is set, one unless auto_paginate is set, otherwise max_pages
+// clamped to maxPagesLimit.
+func maxPagesArg(arguments map[string]interface{}) int {
+	if allPages, _ := arguments["all_pages"].(bool); allPages {
+		return maxPagesLimit
+	}
+
+	autoPaginate, _ := arguments["auto_paginate"].(bool)
+	if !autoPaginate {
+		return 1
+	}
+
+	maxPages, _ := arguments["max_pages"].(float64)
+	if maxPages <= 0 {
+		return defaultMaxPages
+	}
+	if maxPages > maxPagesLimit {
+		return maxPagesLimit
+	}
+	return int(maxPages)
+}
+
+// addPaginationInfo records how many pages were fetched and whether the page
+// cap was hit before the list was exhausted.
+func addPaginationInfo(result map[string]interface{}, pagesFetched int, nextPageToken string) {
+	result["pagesFetched"] = pagesFetched
+	result["morePages"] = nextPageToken != ""
+	if nextPageToken != "" {
+		result["nextPageToken"] = nextPageToken
+	}
+}
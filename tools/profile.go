@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withProfile adds the optional "profile" argument that selects which
+// credential profile a tool call runs against.
+func withProfile() mcp.ToolOption {
+	return mcp.WithString("profile", mcp.Description("Credential profile to use, as named in GOOGLE_PROFILES_DIR (default: the account from GOOGLE_CREDENTIALS_FILE/GOOGLE_TOKEN_FILE)"))
+}
+
+// profileArg returns the requested profile, or "" for the default account.
+func profileArg(arguments map[string]interface{}) string {
+	profile, _ := arguments["profile"].(string)
+	return profile
+}
+
+var authenticatedEmails sync.Map
+
+// authenticatedEmail returns the email address of the account behind profile.
+// It is looked up once through the Gmail profile endpoint, which every token
+// issued by this server has scope for, and cached afterwards.
+func authenticatedEmail(profile string) (string, error) {
+	if email, ok := authenticatedEmails.Load(profile); ok {
+		return email.(string), nil
+	}
+
+	gmailProfile, err := gmailService(profile).Users.GetProfile("me").Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %v", err)
+	}
+	authenticatedEmails.Store(profile, gmailProfile.EmailAddress)
+
+	return gmailProfile.EmailAddress, nil
+}
@@ -1,55 +1,58 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/xml"
 	"fmt"
+	"html"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/nguyenvanduocit/google-mcp/services"
 	"github.com/nguyenvanduocit/google-mcp/util"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 	"gopkg.in/yaml.v3"
 )
 
-var youtubeService = sync.OnceValue(func() *youtube.Service {
-	ctx := context.Background()
-
-	tokenFile := os.Getenv("GOOGLE_TOKEN_FILE")
-	if tokenFile == "" {
-		panic("GOOGLE_TOKEN_FILE environment variable must be set")
-	}
-
-	credentialsFile := os.Getenv("GOOGLE_CREDENTIALS_FILE")
-	if credentialsFile == "" {
-		panic("GOOGLE_CREDENTIALS_FILE environment variable must be set")
-	}
-
-	client := services.GoogleHttpClient(tokenFile, credentialsFile)
+var youtubeServices = services.NewProfileCache(func(client *http.Client) (*youtube.Service, error) {
+	return youtube.NewService(context.Background(), option.WithHTTPClient(client))
+})
 
-	srv, err := youtube.NewService(ctx, option.WithHTTPClient(client))
+// youtubeService returns the YouTube service for the given credential profile ("" selects the default account).
+func youtubeService(profile string) *youtube.Service {
+	srv, err := youtubeServices.Get(profile)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create YouTube service: %v", err))
 	}
-
 	return srv
-})
+}
 
 func RegisterYouTubeTools(s *server.MCPServer) {
 	videoTool := mcp.NewTool("youtube_video",
-		mcp.WithDescription("List or get YouTube videos from authenticated user's channel"),
-		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, get")),
+		mcp.WithDescription("List or get YouTube videos from authenticated user's channel, or list the video categories of a region. The list action uses search at 100 quota units per page and follows at most 3 pages even with all_pages; use youtube_list_uploads to page through every upload at 1 unit per page"),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, get, categories")),
 		mcp.WithString("video_id", mcp.Description("Video ID (required for 'get' action)")),
 		mcp.WithString("query", mcp.Description("Search query to filter videos (optional for 'list' action)")),
 		mcp.WithNumber("max_results", mcp.Description("Maximum results to return (default: 10, list action)")),
 		mcp.WithString("order", mcp.Description("Sort order: date, rating, relevance, title, viewCount (default: date, list action)")),
+		mcp.WithBoolean("verbose", mcp.Description("Include processing details, file details and suggestions (owner only, costs extra quota; get action)")),
+		mcp.WithString("content_owner", mcp.Description("YouTube partner content owner ID to act on behalf of (get action; requires a partner account linked to that content owner)")),
+		withYouTubeLocale(),
+		withAutoPaginate(),
+		withProfile(),
 	)
-	s.AddTool(videoTool, util.ErrorGuard(youtubeVideoHandler))
+	s.AddTool(videoTool, util.ErrorGuardNamed(videoTool.Name, youtubeVideoHandler))
 
 	videoUpdateTool := mcp.NewTool("youtube_video_update",
 		mcp.WithDescription("Update metadata for a YouTube video"),
@@ -59,27 +62,61 @@ func RegisterYouTubeTools(s *server.MCPServer) {
 		mcp.WithString("tags", mcp.Description("Comma-separated tags")),
 		mcp.WithString("category_id", mcp.Description("YouTube category ID (e.g., '22' for People & Blogs)")),
 		mcp.WithString("privacy_status", mcp.Description("Privacy status: public, unlisted, private")),
+		mcp.WithString("publish_at", mcp.Description("Schedule the video to go public at this future time (RFC3339). The video is set to private until then")),
+		withProfile(),
 	)
-	s.AddTool(videoUpdateTool, util.ErrorGuard(youtubeVideoUpdateHandler))
+	s.AddTool(videoUpdateTool, util.ErrorGuardNamed(videoUpdateTool.Name, youtubeVideoUpdateHandler))
 
 	commentsTool := mcp.NewTool("youtube_comments",
-		mcp.WithDescription("Manage YouTube video comments - list, post, or reply"),
-		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, post, reply")),
+		mcp.WithDescription("Manage YouTube video comments - list, get, post, or reply"),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: list, get, post, reply")),
 		mcp.WithString("video_id", mcp.Description("Video ID (required for list/post actions)")),
-		mcp.WithString("comment_id", mcp.Description("Comment ID (required for reply action)")),
+		mcp.WithString("comment_id", mcp.Description("Top-level comment ID (required for get/reply actions)")),
 		mcp.WithString("text", mcp.Description("Comment text (required for post/reply actions)")),
-		mcp.WithNumber("max_results", mcp.Description("Maximum comments to return (default: 20, list action)")),
+		mcp.WithNumber("max_results", mcp.Description("Maximum comments to return (default: 20, list action; per page when auto_paginate is set)")),
 		mcp.WithString("order", mcp.Description("Sort order: time, relevance (default: time, list action)")),
+		mcp.WithString("sort_by", mcp.Description("Set to 'likes' to fetch relevance-ordered comments and return the top max_results by like count (list action)")),
+		mcp.WithString("output_format", mcp.Description("Output format for the list action: yaml (default) or csv")),
+		withAutoPaginate(),
+		withProfile(),
 	)
-	s.AddTool(commentsTool, util.ErrorGuard(youtubeCommentsHandler))
+	s.AddTool(commentsTool, util.ErrorGuardNamed(commentsTool.Name, youtubeCommentsHandler))
 
 	captionsTool := mcp.NewTool("youtube_captions",
-		mcp.WithDescription("Download captions/transcript from a YouTube video"),
+		mcp.WithDescription("Download captions/transcript from a YouTube video. Falls back to the auto-generated transcript, labelled as such, when no caption track can be downloaded"),
 		mcp.WithString("video_id", mcp.Required(), mcp.Description("Video ID to get captions from")),
 		mcp.WithString("language", mcp.Description("Language code (e.g., 'en', 'vi'). Default: first available")),
 		mcp.WithString("format", mcp.Description("Output format: text (plain text, default), srt, vtt")),
+		mcp.WithBoolean("all_languages", mcp.Description("Download every available caption track, keyed by language code; language is ignored (default: false)")),
+		withProfile(),
+	)
+	s.AddTool(captionsTool, util.ErrorGuardNamed(captionsTool.Name, youtubeCaptionsHandler))
+
+	thumbnailTool := mcp.NewTool("youtube_set_thumbnail",
+		mcp.WithDescription("Set a custom thumbnail for a YouTube video from a local image file or an image URL"),
+		mcp.WithString("video_id", mcp.Required(), mcp.Description("Video ID to set the thumbnail for")),
+		mcp.WithString("file_path", mcp.Description("Path to a local JPEG or PNG image (either file_path or image_url is required)")),
+		mcp.WithString("image_url", mcp.Description("HTTP(S) URL of a JPEG or PNG image to fetch and upload (either file_path or image_url is required)")),
+		withProfile(),
+	)
+	s.AddTool(thumbnailTool, util.ErrorGuardNamed(thumbnailTool.Name, youtubeSetThumbnailHandler))
+
+	uploadsTool := mcp.NewTool("youtube_list_uploads",
+		mcp.WithDescription("List the authenticated channel's uploaded videos, newest first, from its uploads playlist. Cheaper than youtube_video list (1 quota unit per page instead of 100) and includes recent uploads the search index may not have yet"),
+		mcp.WithNumber("max_results", mcp.Description("Maximum results per page (default: 10, max: 50)")),
+		mcp.WithString("page_token", mcp.Description("Page token from a previous call")),
+		withAutoPaginate(),
+		withProfile(),
 	)
-	s.AddTool(captionsTool, util.ErrorGuard(youtubeCaptionsHandler))
+	s.AddTool(uploadsTool, util.ErrorGuardNamed(uploadsTool.Name, youtubeListUploadsHandler))
+
+	channelAuditTool := mcp.NewTool("youtube_channel_audit",
+		mcp.WithDescription("Audit a channel's standing and content owner link: community guidelines, copyright strike and Content ID claim standing, and the linked partner content owner. Requires a YouTube partner (content owner) account; other accounts get a permission error"),
+		mcp.WithString("channel_id", mcp.Description("Channel ID to audit (default: the authenticated channel, or every channel the content owner manages when content_owner is set)")),
+		mcp.WithString("content_owner", mcp.Description("YouTube partner content owner ID to act on behalf of")),
+		withProfile(),
+	)
+	s.AddTool(channelAuditTool, util.ErrorGuardNamed(channelAuditTool.Name, youtubeChannelAuditHandler))
 }
 
 // Video handlers
@@ -92,52 +129,270 @@ func youtubeVideoHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		return youtubeListVideosHandler(arguments)
 	case "get":
 		return youtubeGetVideoHandler(arguments)
+	case "categories":
+		return youtubeListCategoriesHandler(arguments)
 	default:
-		return mcp.NewToolResultError("Invalid action. Must be one of: list, get"), nil
+		return mcp.NewToolResultError("Invalid action. Must be one of: list, get, categories"), nil
+	}
+}
+
+// withYouTubeLocale adds the region_code and relevance_language arguments.
+func withYouTubeLocale() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("region_code", mcp.Description("ISO 3166-1 alpha-2 country code to localize results for, e.g. VN or DE (list/categories actions; default: YOUTUBE_REGION_CODE)"))(t)
+		mcp.WithString("relevance_language", mcp.Description("ISO 639-1 language code to prefer in results, e.g. vi or de (list action; for categories, the language of category names; default: YOUTUBE_RELEVANCE_LANGUAGE)"))(t)
+	}
+}
+
+// youtubeLocaleArgs returns the region_code and relevance_language arguments,
+// falling back to the YOUTUBE_REGION_CODE and YOUTUBE_RELEVANCE_LANGUAGE
+// environment variables.
+func youtubeLocaleArgs(arguments map[string]interface{}) (regionCode string, language string) {
+	regionCode, _ = arguments["region_code"].(string)
+	if regionCode == "" {
+		regionCode = os.Getenv("YOUTUBE_REGION_CODE")
+	}
+	language, _ = arguments["relevance_language"].(string)
+	if language == "" {
+		language = os.Getenv("YOUTUBE_RELEVANCE_LANGUAGE")
+	}
+	return strings.ToUpper(regionCode), language
+}
+
+func youtubeListCategoriesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	regionCode, language := youtubeLocaleArgs(arguments)
+	if regionCode == "" {
+		regionCode = "US"
+	}
+
+	listCall := youtubeService(profile).VideoCategories.List([]string{"snippet"}).RegionCode(regionCode)
+	if language != "" {
+		listCall = listCall.Hl(language)
 	}
+
+	recordYouTubeQuota("videoCategories.list")
+	resp, err := listCall.Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list video categories: %v", err)), nil
+	}
+
+	categories := make([]map[string]interface{}, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		categories = append(categories, map[string]interface{}{
+			"id":         item.Id,
+			"title":      item.Snippet.Title,
+			"assignable": item.Snippet.Assignable,
+		})
+	}
+
+	result := map[string]interface{}{
+		"region":     regionCode,
+		"count":      len(categories),
+		"categories": categories,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+// youtubeVideoFields selects the fields of listed videos for the configured
+// output verbosity.
+var youtubeVideoFields = util.FieldSet{
+	Terse:   []string{"video_id", "title"},
+	Verbose: []string{"channel_title"},
+}
+
+// youtubeCommentFields selects the fields of listed comment threads for the
+// configured output verbosity.
+var youtubeCommentFields = util.FieldSet{
+	Terse:   []string{"comment_id", "text"},
+	Verbose: []string{"author_channel_id"},
+}
+
+// maxYouTubeSearchPages caps the pages the list action fetches, since each
+// search.list page costs 100 of the default 10,000 daily quota units.
+const maxYouTubeSearchPages = 3
+
 func youtubeListVideosHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	query, _ := arguments["query"].(string)
 	maxResults, ok := arguments["max_results"].(float64)
 	if !ok || maxResults <= 0 {
-		maxResults = 10
+		maxResults = float64(util.DefaultPageSizes().YouTubeVideos)
 	}
 	order, _ := arguments["order"].(string)
 	if order == "" {
 		order = "date"
 	}
+	regionCode, language := youtubeLocaleArgs(arguments)
+
+	maxPages := min(maxPagesArg(arguments), maxYouTubeSearchPages)
+
+	items := make([]*youtube.SearchResult, 0)
+	pageToken := ""
+	pagesFetched := 0
+	var quotaCost int64
+	for pagesFetched < maxPages {
+		searchCall := youtubeService(profile).Search.List([]string{"snippet"}).
+			ForMine(true).
+			Type("video").
+			MaxResults(int64(maxResults)).
+			Order(order)
+
+		if query != "" {
+			searchCall = searchCall.Q(query)
+		}
+		if regionCode != "" {
+			searchCall = searchCall.RegionCode(regionCode)
+		}
+		if language != "" {
+			searchCall = searchCall.RelevanceLanguage(language)
+		}
+		if pageToken != "" {
+			searchCall = searchCall.PageToken(pageToken)
+		}
 
-	searchCall := youtubeService().Search.List([]string{"snippet"}).
-		ForMine(true).
-		Type("video").
-		MaxResults(int64(maxResults)).
-		Order(order)
+		quotaCost += recordYouTubeQuota("search.list")
+		resp, err := searchCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list videos: %v", err)), nil
+		}
+		pagesFetched++
 
-	if query != "" {
-		searchCall = searchCall.Q(query)
+		items = append(items, resp.Items...)
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
 	}
 
-	resp, err := searchCall.Do()
+	videos := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		videoInfo := map[string]interface{}{
+			"video_id":      item.Id.VideoId,
+			"title":         item.Snippet.Title,
+			"published_at":  item.Snippet.PublishedAt,
+			"description":   item.Snippet.Description,
+			"channel_title": item.Snippet.ChannelTitle,
+		}
+		videos = append(videos, videoInfo)
+	}
+	youtubeVideoFields.Select(videos)
+
+	result := map[string]interface{}{
+		"count":     len(videos),
+		"videos":    videos,
+		"quotaCost": quotaCost,
+		"quotaNote": fmt.Sprintf("Listing costs %d quota units per page; prefer the get action (1 unit) when the video ID is known, or youtube_list_uploads (1 unit per page) to list your uploads", youtubeQuotaCosts["search.list"]),
+	}
+	if maxPages > 1 {
+		addPaginationInfo(result, pagesFetched, pageToken)
+	}
+
+	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list videos: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
 	}
 
-	videos := make([]map[string]interface{}, 0, len(resp.Items))
-	for _, item := range resp.Items {
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// uploadsPlaylists caches the uploads playlist ID of each profile's channel,
+// which never changes.
+var uploadsPlaylists sync.Map
+
+// uploadsPlaylistID returns the ID of the playlist holding every video
+// uploaded by the authenticated user's channel.
+func uploadsPlaylistID(profile string) (string, error) {
+	if id, ok := uploadsPlaylists.Load(profile); ok {
+		return id.(string), nil
+	}
+
+	recordYouTubeQuota("channels.list")
+	resp, err := youtubeService(profile).Channels.List([]string{"contentDetails"}).Mine(true).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get channel: %v", err)
+	}
+	if len(resp.Items) == 0 || resp.Items[0].ContentDetails == nil || resp.Items[0].ContentDetails.RelatedPlaylists == nil {
+		return "", fmt.Errorf("the authenticated account has no YouTube channel")
+	}
+
+	id := resp.Items[0].ContentDetails.RelatedPlaylists.Uploads
+	uploadsPlaylists.Store(profile, id)
+	return id, nil
+}
+
+func youtubeListUploadsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	maxResults, ok := arguments["max_results"].(float64)
+	if !ok || maxResults <= 0 {
+		maxResults = float64(util.DefaultPageSizes().YouTubeVideos)
+	}
+	if maxResults > 50 {
+		maxResults = 50
+	}
+	pageToken, _ := arguments["page_token"].(string)
+
+	playlistID, err := uploadsPlaylistID(profile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	maxPages := maxPagesArg(arguments)
+
+	items := make([]*youtube.PlaylistItem, 0)
+	pagesFetched := 0
+	for pagesFetched < maxPages {
+		listCall := youtubeService(profile).PlaylistItems.List([]string{"snippet", "contentDetails"}).
+			PlaylistId(playlistID).
+			MaxResults(int64(maxResults))
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		recordYouTubeQuota("playlistItems.list")
+		resp, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list uploads: %v", err)), nil
+		}
+		pagesFetched++
+
+		items = append(items, resp.Items...)
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	videos := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
 		videoInfo := map[string]interface{}{
-			"video_id":     item.Id.VideoId,
-			"title":        item.Snippet.Title,
-			"published_at": item.Snippet.PublishedAt,
-			"description":  item.Snippet.Description,
+			"title":       item.Snippet.Title,
+			"description": item.Snippet.Description,
 		}
+		if item.ContentDetails != nil {
+			videoInfo["video_id"] = item.ContentDetails.VideoId
+			videoInfo["published_at"] = item.ContentDetails.VideoPublishedAt
+		}
+		videoInfo["channel_title"] = item.Snippet.ChannelTitle
 		videos = append(videos, videoInfo)
 	}
+	youtubeVideoFields.Select(videos)
 
 	result := map[string]interface{}{
 		"count":  len(videos),
 		"videos": videos,
 	}
+	if maxPages > 1 {
+		addPaginationInfo(result, pagesFetched, pageToken)
+	} else if pageToken != "" {
+		result["nextPageToken"] = pageToken
+	}
 
 	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
@@ -148,14 +403,27 @@ func youtubeListVideosHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 }
 
 func youtubeGetVideoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	videoID, _ := arguments["video_id"].(string)
 	if videoID == "" {
 		return mcp.NewToolResultError("video_id is required for 'get' action"), nil
 	}
 
-	resp, err := youtubeService().Videos.List([]string{"snippet", "statistics", "contentDetails", "status"}).
-		Id(videoID).
-		Do()
+	verbose, _ := arguments["verbose"].(bool)
+	contentOwner, _ := arguments["content_owner"].(string)
+
+	parts := []string{"snippet", "statistics", "contentDetails", "status"}
+	if verbose {
+		parts = append(parts, "processingDetails", "fileDetails", "suggestions")
+	}
+
+	listCall := youtubeService(profile).Videos.List(parts).Id(videoID)
+	if contentOwner != "" {
+		listCall = listCall.OnBehalfOfContentOwner(contentOwner)
+	}
+
+	recordYouTubeQuota("videos.list")
+	resp, err := listCall.Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get video: %v", err)), nil
 	}
@@ -185,9 +453,68 @@ func youtubeGetVideoHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		videoInfo["duration"] = video.ContentDetails.Duration
 	}
 
+	if policy := youtubeVideoPolicy(video); len(policy) > 0 {
+		videoInfo["policy"] = policy
+	}
+	if contentOwner != "" {
+		videoInfo["content_owner"] = contentOwner
+	}
+
 	if video.Status != nil {
 		videoInfo["privacy_status"] = video.Status.PrivacyStatus
 		videoInfo["upload_status"] = video.Status.UploadStatus
+		if video.Status.FailureReason != "" {
+			videoInfo["failure_reason"] = video.Status.FailureReason
+		}
+		if video.Status.RejectionReason != "" {
+			videoInfo["rejection_reason"] = video.Status.RejectionReason
+		}
+	}
+
+	if video.ProcessingDetails != nil {
+		processing := map[string]interface{}{
+			"status":             video.ProcessingDetails.ProcessingStatus,
+			"file_details":       video.ProcessingDetails.FileDetailsAvailability,
+			"processing_issues":  video.ProcessingDetails.ProcessingIssuesAvailability,
+			"editor_suggestions": video.ProcessingDetails.EditorSuggestionsAvailability,
+			"tag_suggestions":    video.ProcessingDetails.TagSuggestionsAvailability,
+		}
+		if video.ProcessingDetails.ProcessingFailureReason != "" {
+			processing["failure_reason"] = video.ProcessingDetails.ProcessingFailureReason
+		}
+		if progress := video.ProcessingDetails.ProcessingProgress; progress != nil {
+			processing["parts_processed"] = progress.PartsProcessed
+			processing["parts_total"] = progress.PartsTotal
+			processing["time_left_ms"] = progress.TimeLeftMs
+		}
+		videoInfo["processing"] = processing
+	}
+
+	if video.FileDetails != nil {
+		videoInfo["file"] = map[string]interface{}{
+			"name":        video.FileDetails.FileName,
+			"size":        video.FileDetails.FileSize,
+			"type":        video.FileDetails.FileType,
+			"container":   video.FileDetails.Container,
+			"duration_ms": video.FileDetails.DurationMs,
+		}
+	}
+
+	if video.Suggestions != nil {
+		suggestions := map[string]interface{}{}
+		if len(video.Suggestions.ProcessingErrors) > 0 {
+			suggestions["processing_errors"] = video.Suggestions.ProcessingErrors
+		}
+		if len(video.Suggestions.ProcessingWarnings) > 0 {
+			suggestions["processing_warnings"] = video.Suggestions.ProcessingWarnings
+		}
+		if len(video.Suggestions.ProcessingHints) > 0 {
+			suggestions["processing_hints"] = video.Suggestions.ProcessingHints
+		}
+		if len(video.Suggestions.EditorSuggestions) > 0 {
+			suggestions["editor_suggestions"] = video.Suggestions.EditorSuggestions
+		}
+		videoInfo["suggestions"] = suggestions
 	}
 
 	yamlResult, err := yaml.Marshal(videoInfo)
@@ -198,21 +525,132 @@ func youtubeGetVideoHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+// youtubeVideoPolicy collects the ownership and policy fields of a video: the
+// license, whether it is claimed content, and where and for whom it is
+// restricted.
+func youtubeVideoPolicy(video *youtube.Video) map[string]interface{} {
+	policy := map[string]interface{}{}
+	if video.ContentDetails != nil {
+		policy["licensed_content"] = video.ContentDetails.LicensedContent
+		if restriction := video.ContentDetails.RegionRestriction; restriction != nil {
+			if len(restriction.Allowed) > 0 {
+				policy["allowed_regions"] = restriction.Allowed
+			}
+			if len(restriction.Blocked) > 0 {
+				policy["blocked_regions"] = restriction.Blocked
+			}
+		}
+		if rating := video.ContentDetails.ContentRating; rating != nil && rating.YtRating != "" {
+			policy["age_restricted"] = rating.YtRating == "ytAgeRestricted"
+		}
+	}
+	if video.Status != nil {
+		if video.Status.License != "" {
+			policy["license"] = video.Status.License
+		}
+		policy["embeddable"] = video.Status.Embeddable
+		policy["made_for_kids"] = video.Status.MadeForKids
+	}
+	return policy
+}
+
+// Channel audit handler
+
+func youtubeChannelAuditHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	channelID, _ := arguments["channel_id"].(string)
+	contentOwner, _ := arguments["content_owner"].(string)
+
+	listCall := youtubeService(profile).Channels.List([]string{"snippet", "auditDetails", "contentOwnerDetails"})
+	if contentOwner != "" {
+		listCall = listCall.OnBehalfOfContentOwner(contentOwner)
+	}
+	switch {
+	case channelID != "":
+		listCall = listCall.Id(channelID)
+	case contentOwner != "":
+		listCall = listCall.ManagedByMe(true)
+	default:
+		listCall = listCall.Mine(true)
+	}
+
+	recordYouTubeQuota("channels.list")
+	resp, err := listCall.Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to audit channel: %v\nChannel audits need the youtubepartner-channel-audit scope on an account linked to a YouTube partner content owner", err)), nil
+	}
+	if len(resp.Items) == 0 {
+		return mcp.NewToolResultError("no channel found"), nil
+	}
+
+	channels := make([]map[string]interface{}, 0, len(resp.Items))
+	for _, channel := range resp.Items {
+		channelInfo := map[string]interface{}{
+			"channel_id": channel.Id,
+		}
+		if channel.Snippet != nil {
+			channelInfo["title"] = channel.Snippet.Title
+		}
+		if audit := channel.AuditDetails; audit != nil {
+			channelInfo["community_guidelines_good_standing"] = audit.CommunityGuidelinesGoodStanding
+			channelInfo["copyright_strikes_good_standing"] = audit.CopyrightStrikesGoodStanding
+			channelInfo["content_id_claims_good_standing"] = audit.ContentIdClaimsGoodStanding
+		} else {
+			channelInfo["audit"] = "unavailable: the account is not authorized for channel audits"
+		}
+		if owner := channel.ContentOwnerDetails; owner != nil && owner.ContentOwner != "" {
+			channelInfo["content_owner"] = owner.ContentOwner
+			channelInfo["content_owner_linked_at"] = owner.TimeLinked
+		}
+		channels = append(channels, channelInfo)
+	}
+
+	result := map[string]interface{}{
+		"count":    len(channels),
+		"channels": channels,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
 // Video update handler
 
 func youtubeVideoUpdateHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	videoID, _ := arguments["video_id"].(string)
 	title, _ := arguments["title"].(string)
 	description, _ := arguments["description"].(string)
 	tagsStr, _ := arguments["tags"].(string)
 	categoryID, _ := arguments["category_id"].(string)
 	privacyStatus, _ := arguments["privacy_status"].(string)
+	publishAtStr, _ := arguments["publish_at"].(string)
+
+	var publishAt time.Time
+	if publishAtStr != "" {
+		t, err := util.ParseTime(publishAtStr)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid publish_at: %v", err)), nil
+		}
+		if !t.After(time.Now()) {
+			return mcp.NewToolResultError(fmt.Sprintf("publish_at %s is not in the future", t.Format(time.RFC3339))), nil
+		}
+		if privacyStatus != "" && privacyStatus != "private" {
+			return mcp.NewToolResultError("publish_at requires privacy_status private; the video becomes public at the scheduled time"), nil
+		}
+		publishAt = t
+		privacyStatus = "private"
+	}
 
 	needsSnippet := title != "" || description != "" || tagsStr != "" || categoryID != ""
 	needsStatus := privacyStatus != ""
 
 	if !needsSnippet && !needsStatus {
-		return mcp.NewToolResultError("no fields to update. Provide at least one of: title, description, tags, category_id, privacy_status"), nil
+		return mcp.NewToolResultError("no fields to update. Provide at least one of: title, description, tags, category_id, privacy_status, publish_at"), nil
 	}
 
 	// Fetch only the parts we need to update
@@ -224,7 +662,8 @@ func youtubeVideoUpdateHandler(arguments map[string]interface{}) (*mcp.CallToolR
 		fetchParts = append(fetchParts, "status")
 	}
 
-	resp, err := youtubeService().Videos.List(fetchParts).
+	recordYouTubeQuota("videos.list")
+	resp, err := youtubeService(profile).Videos.List(fetchParts).
 		Id(videoID).
 		Do()
 	if err != nil {
@@ -257,13 +696,20 @@ func youtubeVideoUpdateHandler(arguments map[string]interface{}) (*mcp.CallToolR
 
 	if needsStatus {
 		video.Status.PrivacyStatus = privacyStatus
+		if !publishAt.IsZero() {
+			video.Status.PublishAt = publishAt.UTC().Format(time.RFC3339)
+		}
 	}
 
-	_, err = youtubeService().Videos.Update(fetchParts, video).Do()
+	recordYouTubeQuota("videos.update")
+	_, err = youtubeService(profile).Videos.Update(fetchParts, video).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to update video: %v", err)), nil
 	}
 
+	if !publishAt.IsZero() {
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully updated video %s; it will be published at %s", videoID, publishAt.Format(time.RFC3339))), nil
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully updated video %s", videoID)), nil
 }
 
@@ -275,16 +721,22 @@ func youtubeCommentsHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 	switch action {
 	case "list":
 		return youtubeListCommentsHandler(arguments)
+	case "get":
+		return youtubeGetCommentThreadHandler(arguments)
 	case "post":
 		return youtubePostCommentHandler(arguments)
 	case "reply":
 		return youtubeReplyCommentHandler(arguments)
 	default:
-		return mcp.NewToolResultError("Invalid action. Must be one of: list, post, reply"), nil
+		return mcp.NewToolResultError("Invalid action. Must be one of: list, get, post, reply"), nil
 	}
 }
 
+// maxCommentsPerPage is the largest page size commentThreads.list accepts.
+const maxCommentsPerPage = 100
+
 func youtubeListCommentsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	videoID, _ := arguments["video_id"].(string)
 	if videoID == "" {
 		return mcp.NewToolResultError("video_id is required for 'list' action"), nil
@@ -292,25 +744,69 @@ func youtubeListCommentsHandler(arguments map[string]interface{}) (*mcp.CallTool
 
 	maxResults, ok := arguments["max_results"].(float64)
 	if !ok || maxResults <= 0 {
-		maxResults = 20
+		maxResults = float64(util.DefaultPageSizes().YouTubeComments)
 	}
 	order, _ := arguments["order"].(string)
 	if order == "" {
 		order = "time"
 	}
+	sortBy, _ := arguments["sort_by"].(string)
+	if sortBy != "" && sortBy != "likes" {
+		return mcp.NewToolResultError("Invalid sort_by. Must be one of: likes"), nil
+	}
+	outputFormat, _ := arguments["output_format"].(string)
+	if outputFormat != "" && outputFormat != "yaml" && outputFormat != "csv" {
+		return mcp.NewToolResultError("Invalid output_format. Must be one of: yaml, csv"), nil
+	}
 
-	resp, err := youtubeService().CommentThreads.List([]string{"snippet", "replies"}).
-		VideoId(videoID).
-		MaxResults(int64(maxResults)).
-		Order(order).
-		TextFormat("plainText").
-		Do()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list comments: %v", err)), nil
+	maxPages := maxPagesArg(arguments)
+	topN := int(maxResults)
+	if sortBy == "likes" {
+		// Relevance order is only loosely tied to likes, so fetch a full page
+		// of candidates and rank them by like count here
+		order = "relevance"
+		maxResults = maxCommentsPerPage
+	}
+
+	threads := make([]*youtube.CommentThread, 0)
+	pageToken := ""
+	pagesFetched := 0
+	for pagesFetched < maxPages {
+		listCall := youtubeService(profile).CommentThreads.List([]string{"snippet", "replies"}).
+			VideoId(videoID).
+			MaxResults(int64(maxResults)).
+			Order(order).
+			TextFormat("plainText")
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		recordYouTubeQuota("commentThreads.list")
+		resp, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list comments: %v", err)), nil
+		}
+		pagesFetched++
+
+		threads = append(threads, resp.Items...)
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	candidates := len(threads)
+	if sortBy == "likes" {
+		sort.SliceStable(threads, func(i, j int) bool {
+			return threads[i].Snippet.TopLevelComment.Snippet.LikeCount > threads[j].Snippet.TopLevelComment.Snippet.LikeCount
+		})
+		if len(threads) > topN {
+			threads = threads[:topN]
+		}
 	}
 
-	comments := make([]map[string]interface{}, 0, len(resp.Items))
-	for _, thread := range resp.Items {
+	comments := make([]map[string]interface{}, 0, len(threads))
+	for _, thread := range threads {
 		topComment := thread.Snippet.TopLevelComment
 		commentInfo := map[string]interface{}{
 			"comment_id":   topComment.Id,
@@ -320,29 +816,58 @@ func youtubeListCommentsHandler(arguments map[string]interface{}) (*mcp.CallTool
 			"published_at": topComment.Snippet.PublishedAt,
 			"reply_count":  thread.Snippet.TotalReplyCount,
 		}
+		if topComment.Snippet.AuthorChannelId != nil {
+			commentInfo["author_channel_id"] = topComment.Snippet.AuthorChannelId.Value
+		}
 
 		if thread.Replies != nil && len(thread.Replies.Comments) > 0 {
 			replies := make([]map[string]interface{}, 0, len(thread.Replies.Comments))
 			for _, reply := range thread.Replies.Comments {
-				replyInfo := map[string]interface{}{
-					"comment_id":   reply.Id,
-					"author":       reply.Snippet.AuthorDisplayName,
-					"text":         reply.Snippet.TextDisplay,
-					"likes":        reply.Snippet.LikeCount,
-					"published_at": reply.Snippet.PublishedAt,
-				}
-				replies = append(replies, replyInfo)
+				replies = append(replies, youtubeCommentInfo(reply))
 			}
 			commentInfo["replies"] = replies
 		}
 
 		comments = append(comments, commentInfo)
 	}
+	youtubeCommentFields.Select(comments)
+
+	if outputFormat == "csv" {
+		// Flatten replies into their own rows, linked by parent_id
+		rows := make([]map[string]interface{}, 0, len(comments))
+		for _, comment := range comments {
+			replies, _ := comment["replies"].([]map[string]interface{})
+			row := make(map[string]interface{}, len(comment))
+			for key, value := range comment {
+				if key != "replies" {
+					row[key] = value
+				}
+			}
+			rows = append(rows, row)
+			for _, reply := range replies {
+				reply["parent_id"] = comment["comment_id"]
+				rows = append(rows, reply)
+			}
+		}
+
+		csvResult, err := util.MapsToCSV(rows, youtubeCommentFields.Columns("comment_id", "parent_id", "author", "published_at", "likes", "reply_count", "text")...)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to format comments as CSV: %v", err)), nil
+		}
+		return mcp.NewToolResultText(csvResult), nil
+	}
 
 	result := map[string]interface{}{
 		"count":    len(comments),
 		"comments": comments,
 	}
+	if sortBy == "likes" {
+		result["sortedBy"] = "likes"
+		result["candidatesRanked"] = candidates
+	}
+	if maxPages > 1 {
+		addPaginationInfo(result, pagesFetched, pageToken)
+	}
 
 	yamlResult, err := yaml.Marshal(result)
 	if err != nil {
@@ -352,7 +877,79 @@ func youtubeListCommentsHandler(arguments map[string]interface{}) (*mcp.CallTool
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+func youtubeGetCommentThreadHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	commentID, _ := arguments["comment_id"].(string)
+	if commentID == "" {
+		return mcp.NewToolResultError("comment_id is required for 'get' action"), nil
+	}
+
+	recordYouTubeQuota("commentThreads.list")
+	resp, err := youtubeService(profile).CommentThreads.List([]string{"snippet"}).
+		Id(commentID).
+		TextFormat("plainText").
+		Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get comment thread: %v", err)), nil
+	}
+	if len(resp.Items) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("comment thread not found: %s", commentID)), nil
+	}
+	thread := resp.Items[0]
+
+	// Thread listings embed only a few replies, so page through all of them
+	replies := make([]map[string]interface{}, 0, thread.Snippet.TotalReplyCount)
+	pageToken := ""
+	for {
+		listCall := youtubeService(profile).Comments.List([]string{"snippet"}).
+			ParentId(commentID).
+			MaxResults(100).
+			TextFormat("plainText")
+		if pageToken != "" {
+			listCall = listCall.PageToken(pageToken)
+		}
+
+		recordYouTubeQuota("comments.list")
+		repliesResp, err := listCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list replies: %v", err)), nil
+		}
+		for _, reply := range repliesResp.Items {
+			replies = append(replies, youtubeCommentInfo(reply))
+		}
+
+		pageToken = repliesResp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	result := youtubeCommentInfo(thread.Snippet.TopLevelComment)
+	result["video_id"] = thread.Snippet.VideoId
+	result["reply_count"] = thread.Snippet.TotalReplyCount
+	result["replies"] = replies
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal comment thread: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// youtubeCommentInfo converts a comment into tool output.
+func youtubeCommentInfo(comment *youtube.Comment) map[string]interface{} {
+	return map[string]interface{}{
+		"comment_id":   comment.Id,
+		"author":       comment.Snippet.AuthorDisplayName,
+		"text":         comment.Snippet.TextDisplay,
+		"likes":        comment.Snippet.LikeCount,
+		"published_at": comment.Snippet.PublishedAt,
+	}
+}
+
 func youtubePostCommentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	videoID, _ := arguments["video_id"].(string)
 	if videoID == "" {
 		return mcp.NewToolResultError("video_id is required for 'post' action"), nil
@@ -373,7 +970,8 @@ func youtubePostCommentHandler(arguments map[string]interface{}) (*mcp.CallToolR
 		},
 	}
 
-	resp, err := youtubeService().CommentThreads.Insert([]string{"snippet"}, commentThread).Do()
+	recordYouTubeQuota("commentThreads.insert")
+	resp, err := youtubeService(profile).CommentThreads.Insert([]string{"snippet"}, commentThread).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to post comment: %v", err)), nil
 	}
@@ -382,6 +980,7 @@ func youtubePostCommentHandler(arguments map[string]interface{}) (*mcp.CallToolR
 }
 
 func youtubeReplyCommentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	commentID, _ := arguments["comment_id"].(string)
 	if commentID == "" {
 		return mcp.NewToolResultError("comment_id is required for 'reply' action"), nil
@@ -398,7 +997,8 @@ func youtubeReplyCommentHandler(arguments map[string]interface{}) (*mcp.CallTool
 		},
 	}
 
-	resp, err := youtubeService().Comments.Insert([]string{"snippet"}, comment).Do()
+	recordYouTubeQuota("comments.insert")
+	resp, err := youtubeService(profile).Comments.Insert([]string{"snippet"}, comment).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to reply to comment: %v", err)), nil
 	}
@@ -409,21 +1009,58 @@ func youtubeReplyCommentHandler(arguments map[string]interface{}) (*mcp.CallTool
 // Captions handler
 
 func youtubeCaptionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
 	videoID, _ := arguments["video_id"].(string)
 	language, _ := arguments["language"].(string)
 	format, _ := arguments["format"].(string)
 	if format == "" {
 		format = "text"
 	}
+	allLanguages, _ := arguments["all_languages"].(bool)
 
 	// List available caption tracks
-	captionResp, err := youtubeService().Captions.List([]string{"id", "snippet"}, videoID).Do()
+	recordYouTubeQuota("captions.list")
+	captionResp, err := youtubeService(profile).Captions.List([]string{"id", "snippet"}, videoID).Do()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list captions: %v", err)), nil
 	}
 
 	if len(captionResp.Items) == 0 {
-		return mcp.NewToolResultError(fmt.Sprintf("no captions available for video: %s", videoID)), nil
+		return autoTranscriptResult(videoID, language, fmt.Errorf("no captions available for video: %s", videoID))
+	}
+
+	if allLanguages {
+		captions := make(map[string]string)
+		failures := make(map[string]string)
+		for _, caption := range captionResp.Items {
+			lang := caption.Snippet.Language
+			if caption.Snippet.TrackKind != "" && caption.Snippet.TrackKind != "standard" {
+				lang = lang + " (" + strings.ToLower(caption.Snippet.TrackKind) + ")"
+			}
+			content, err := downloadCaption(profile, caption.Id, format)
+			if err != nil {
+				failures[lang] = err.Error()
+				continue
+			}
+			captions[lang] = content
+		}
+
+		result := map[string]interface{}{
+			"video_id":  videoID,
+			"format":    format,
+			"count":     len(captions),
+			"languages": captions,
+		}
+		if len(failures) > 0 {
+			result["failures"] = failures
+		}
+
+		yamlResult, err := yaml.Marshal(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(yamlResult)), nil
 	}
 
 	// Find the right caption track
@@ -441,8 +1078,35 @@ func youtubeCaptionsHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		}
 	}
 
-	// Download the caption
-	downloadCall := youtubeService().Captions.Download(captionID)
+	content, err := downloadCaption(profile, captionID, format)
+	if err != nil {
+		// Tracks of videos the user does not own cannot be downloaded through the API
+		fallbackLang := language
+		if fallbackLang == "" {
+			fallbackLang = captionLang
+		}
+		return autoTranscriptResult(videoID, fallbackLang, err)
+	}
+
+	result := map[string]interface{}{
+		"video_id": videoID,
+		"language": captionLang,
+		"format":   format,
+		"content":  content,
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// downloadCaption downloads a caption track in the requested format: srt, vtt,
+// or text, which is fetched as SRT and stripped of cue numbers and timestamps.
+func downloadCaption(profile string, captionID string, format string) (string, error) {
+	downloadCall := youtubeService(profile).Captions.Download(captionID)
 
 	// Set format for download
 	switch format {
@@ -454,15 +1118,16 @@ func youtubeCaptionsHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		downloadCall = downloadCall.Tfmt("srt") // download as SRT, then strip timestamps
 	}
 
+	recordYouTubeQuota("captions.download")
 	resp, err := downloadCall.Download()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to download captions: %v", err)), nil
+		return "", fmt.Errorf("failed to download captions: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to read caption data: %v", err)), nil
+		return "", fmt.Errorf("failed to read caption data: %v", err)
 	}
 
 	content := string(body)
@@ -472,10 +1137,128 @@ func youtubeCaptionsHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		content = stripSRTFormatting(content)
 	}
 
+	return content, nil
+}
+
+// Thumbnail handler
+
+// maxThumbnailBytes is YouTube's size limit for custom thumbnails.
+const maxThumbnailBytes = 2 * 1024 * 1024
+
+func youtubeSetThumbnailHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	profile := profileArg(arguments)
+	videoID, _ := arguments["video_id"].(string)
+	filePath, _ := arguments["file_path"].(string)
+	imageURL, _ := arguments["image_url"].(string)
+
+	if videoID == "" {
+		return mcp.NewToolResultError("video_id is required"), nil
+	}
+	if (filePath == "") == (imageURL == "") {
+		return mcp.NewToolResultError("exactly one of file_path or image_url is required"), nil
+	}
+
+	var image []byte
+	var err error
+	if filePath != "" {
+		image, err = os.ReadFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read thumbnail file: %v", err)), nil
+		}
+	} else {
+		image, err = fetchThumbnail(imageURL)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	if len(image) > maxThumbnailBytes {
+		return mcp.NewToolResultError(fmt.Sprintf("thumbnail is %d bytes; YouTube allows at most %d bytes", len(image), maxThumbnailBytes)), nil
+	}
+	contentType := http.DetectContentType(image)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported thumbnail type %s; must be image/jpeg or image/png", contentType)), nil
+	}
+
+	recordYouTubeQuota("thumbnails.set")
+	resp, err := youtubeService(profile).Thumbnails.Set(videoID).
+		Media(bytes.NewReader(image), googleapi.ContentType(contentType)).
+		Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to set thumbnail: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"video_id":     videoID,
+		"content_type": contentType,
+		"size":         len(image),
+	}
+	if len(resp.Items) > 0 && resp.Items[0].Default != nil {
+		result["thumbnail_url"] = resp.Items[0].Default.Url
+	}
+
+	yamlResult, err := yaml.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(yamlResult)), nil
+}
+
+// fetchThumbnail downloads an image, rejecting non-image responses and bodies
+// larger than the thumbnail limit before reading them fully.
+func fetchThumbnail(imageURL string) ([]byte, error) {
+	if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
+		return nil, fmt.Errorf("image_url must be an http or https URL")
+	}
+
+	resp, err := services.DefaultHttpClient().Get(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: HTTP %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("image_url returned %s, not an image", contentType)
+	}
+	if resp.ContentLength > maxThumbnailBytes {
+		return nil, fmt.Errorf("image is %d bytes; YouTube allows at most %d bytes", resp.ContentLength, maxThumbnailBytes)
+	}
+
+	image, err := io.ReadAll(io.LimitReader(resp.Body, maxThumbnailBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+
+	return image, nil
+}
+
+// timedTextURL is YouTube's public transcript endpoint, which serves the
+// auto-generated (ASR) captions for videos that permit it.
+const timedTextURL = "https://www.youtube.com/api/timedtext"
+
+// autoTranscriptResult falls back to the auto-generated transcript when no
+// caption track could be downloaded through the API. The output is labelled as
+// auto-generated; if the fallback fails too, the original error is reported.
+func autoTranscriptResult(videoID string, language string, cause error) (*mcp.CallToolResult, error) {
+	if language == "" {
+		language = "en"
+	}
+
+	content, err := fetchAutoTranscript(videoID, language)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%v; auto-generated transcript fallback failed: %v", cause, err)), nil
+	}
+
 	result := map[string]interface{}{
 		"video_id": videoID,
-		"language": captionLang,
-		"format":   format,
+		"language": language,
+		"format":   "text",
+		"source":   "auto-generated",
+		"note":     "Transcript comes from YouTube's automatic speech recognition and may contain errors",
 		"content":  content,
 	}
 
@@ -487,6 +1270,47 @@ func youtubeCaptionsHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 	return mcp.NewToolResultText(string(yamlResult)), nil
 }
 
+// fetchAutoTranscript retrieves the auto-generated transcript for a video from
+// the timedtext endpoint and returns it as plain text, one cue per line.
+func fetchAutoTranscript(videoID string, language string) (string, error) {
+	query := url.Values{}
+	query.Set("v", videoID)
+	query.Set("lang", language)
+	query.Set("kind", "asr")
+
+	resp, err := services.DefaultHttpClient().Get(timedTextURL + "?" + query.Encode())
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch transcript: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch transcript: HTTP %d", resp.StatusCode)
+	}
+
+	var transcript struct {
+		Texts []string `xml:"text"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&transcript); err != nil {
+		if err == io.EOF {
+			return "", fmt.Errorf("no auto-generated transcript available for language %s", language)
+		}
+		return "", fmt.Errorf("failed to parse transcript: %v", err)
+	}
+
+	lines := make([]string, 0, len(transcript.Texts))
+	for _, text := range transcript.Texts {
+		if text = strings.TrimSpace(html.UnescapeString(text)); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if len(lines) == 0 {
+		return "", fmt.Errorf("no auto-generated transcript available for language %s", language)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
 // stripSRTFormatting removes SRT sequence numbers and timestamps, returning plain text
 func stripSRTFormatting(srt string) string {
 	lines := strings.Split(srt, "\n")
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// expensiveQuotaCost is the per-request cost at or above which a YouTube
+// operation is logged as a warning.
+const expensiveQuotaCost = 100
+
+// youtubeQuotaCosts holds the YouTube Data API quota cost of each operation
+// used by the tools, in units of the default 10,000 unit daily quota.
+var youtubeQuotaCosts = map[string]int64{
+	"search.list":           100,
+	"channels.list":         1,
+	"playlistItems.list":    1,
+	"videos.list":           1,
+	"videoCategories.list":  1,
+	"videos.update":         50,
+	"commentThreads.list":   1,
+	"comments.list":         1,
+	"commentThreads.insert": 50,
+	"comments.insert":       50,
+	"captions.list":         50,
+	"captions.download":     200,
+	"thumbnails.set":        50,
+}
+
+// youtubeQuotaUsed is the estimated quota consumed by this process.
+var youtubeQuotaUsed atomic.Int64
+
+// recordYouTubeQuota adds the estimated cost of one YouTube API request to the
+// process total and logs it, warning when the request is expensive. It returns
+// the request's cost.
+func recordYouTubeQuota(operation string) int64 {
+	cost := youtubeQuotaCosts[operation]
+	total := youtubeQuotaUsed.Add(cost)
+	if cost >= expensiveQuotaCost {
+		log.Printf("Warning: YouTube %s is expensive: ~%d quota units (process total: ~%d)", operation, cost, total)
+	} else {
+		log.Printf("YouTube %s: ~%d quota units (process total: ~%d)", operation, cost, total)
+	}
+	return cost
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// DecodeGmailData decodes body and attachment data returned by the Gmail API.
+// Gmail normally uses the padded URL-safe alphabet, but some messages come back
+// unpadded or with the standard alphabet, so each variant is tried in turn.
+func DecodeGmailData(data string) ([]byte, error) {
+	data = strings.TrimSpace(data)
+
+	var firstErr error
+	for _, encoding := range []*base64.Encoding{
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+	} {
+		decoded, err := encoding.DecodeString(data)
+		if err == nil {
+			return decoded, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Trailing padding may be present but incomplete; strip it and retry
+	trimmed := strings.TrimRight(data, "=")
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.RawStdEncoding} {
+		if decoded, err := encoding.DecodeString(trimmed); err == nil {
+			return decoded, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to decode base64 data: %v", firstErr)
+}
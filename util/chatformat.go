@@ -0,0 +1,85 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	chatCodeFencePattern  = regexp.MustCompile("^\\s*```")
+	chatInlineCodePattern = regexp.MustCompile("`[^`\n]+`")
+	chatHeadingPattern    = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	chatBulletPattern     = regexp.MustCompile(`^(\s*)[-+*]\s+`)
+	chatBoldPattern       = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	chatItalicPattern     = regexp.MustCompile(`(^|[^*\w])\*(\S(?:[^*]*?\S)?)\*`)
+	chatStrikePattern     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	chatLinkPattern       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// chatBoldMarker temporarily stands in for bold asterisks so the italic pass
+// does not treat them as emphasis.
+const chatBoldMarker = "\x00"
+
+// MarkdownToChat converts common standard markdown into Google Chat's text
+// formatting syntax:
+//
+//   - **bold** and __bold__ become *bold*
+//   - *italic* becomes _italic_ (_italic_ is left as is)
+//   - ~~strike~~ becomes ~strike~
+//   - `inline code` is kept
+//   - fenced code blocks are kept, minus the language tag, which Chat does not support
+//   - "-", "+" and "*" bullets become "•" bullets, keeping indentation
+//   - # headings become bold lines
+//   - [text](url) links become <url|text>
+//
+// Text inside code spans and code blocks is never changed. Tables, images and
+// block quotes are not supported by Chat and are passed through unchanged.
+func MarkdownToChat(markdown string) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	inCodeBlock := false
+	for i, line := range lines {
+		if chatCodeFencePattern.MatchString(line) {
+			if !inCodeBlock {
+				// Drop the language tag after the opening fence
+				line = line[:strings.Index(line, "```")+3]
+			}
+			inCodeBlock = !inCodeBlock
+			lines[i] = line
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+		lines[i] = convertChatLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// convertChatLine converts one line outside a code block, leaving inline code
+// spans untouched.
+func convertChatLine(line string) string {
+	if match := chatHeadingPattern.FindStringSubmatch(line); match != nil {
+		line = "**" + match[1] + "**"
+	}
+	line = chatBulletPattern.ReplaceAllString(line, "$1• ")
+
+	var b strings.Builder
+	last := 0
+	for _, span := range chatInlineCodePattern.FindAllStringIndex(line, -1) {
+		b.WriteString(convertChatInline(line[last:span[0]]))
+		b.WriteString(line[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(convertChatInline(line[last:]))
+	return b.String()
+}
+
+// convertChatInline converts inline emphasis and links in text that contains
+// no code spans.
+func convertChatInline(text string) string {
+	text = chatLinkPattern.ReplaceAllString(text, "<$2|$1>")
+	text = chatBoldPattern.ReplaceAllString(text, chatBoldMarker+"$1$2"+chatBoldMarker)
+	text = chatItalicPattern.ReplaceAllString(text, "${1}_${2}_")
+	text = chatStrikePattern.ReplaceAllString(text, "~$1~")
+	return strings.ReplaceAll(text, chatBoldMarker, "*")
+}
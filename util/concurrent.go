@@ -0,0 +1,30 @@
+package util
+
+import "sync"
+
+// MapConcurrent calls fn for every item with at most limit calls in flight and
+// returns the results and errors in input order: results[i] and errs[i] belong
+// to items[i]. A limit below one runs the calls sequentially.
+func MapConcurrent[In, Out any](items []In, limit int, fn func(In) (Out, error)) ([]Out, []error) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]Out, len(items))
+	errs := make([]error, len(items))
+	semaphore := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, item In) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i], errs[i] = fn(item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	return results, errs
+}
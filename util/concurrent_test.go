@@ -0,0 +1,110 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapConcurrentPreservesOrder(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3, 0}
+
+	// Later items finish first, so completion order is the reverse of input order
+	results, errs := MapConcurrent(items, len(items), func(n int) (string, error) {
+		time.Sleep(time.Duration(n) * 10 * time.Millisecond)
+		return fmt.Sprintf("item-%d", n), nil
+	})
+
+	if len(results) != len(items) || len(errs) != len(items) {
+		t.Fatalf("got %d results and %d errors, want %d of each", len(results), len(errs), len(items))
+	}
+	for i, n := range items {
+		if want := fmt.Sprintf("item-%d", n); results[i] != want {
+			t.Errorf("results[%d] = %q, want %q", i, results[i], want)
+		}
+		if errs[i] != nil {
+			t.Errorf("errs[%d] = %v, want nil", i, errs[i])
+		}
+	}
+}
+
+func TestMapConcurrentErrorsAtItemIndex(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4, 5, 6}
+	errOdd := errors.New("odd item")
+
+	results, errs := MapConcurrent(items, 3, func(n int) (int, error) {
+		if n%2 == 1 {
+			return 0, fmt.Errorf("item %d: %w", n, errOdd)
+		}
+		return n * 10, nil
+	})
+
+	for i, n := range items {
+		if n%2 == 1 {
+			if !errors.Is(errs[i], errOdd) {
+				t.Errorf("errs[%d] = %v, want an error wrapping %v", i, errs[i], errOdd)
+			}
+			if want := fmt.Sprintf("item %d: odd item", n); errs[i] != nil && errs[i].Error() != want {
+				t.Errorf("errs[%d] = %q, want %q", i, errs[i], want)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("errs[%d] = %v, want nil", i, errs[i])
+		}
+		if results[i] != n*10 {
+			t.Errorf("results[%d] = %d, want %d", i, results[i], n*10)
+		}
+	}
+}
+
+func TestMapConcurrentRespectsLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		wantLimit int64
+	}{
+		{name: "limit 1", limit: 1, wantLimit: 1},
+		{name: "limit 3", limit: 3, wantLimit: 3},
+		{name: "limit below one runs sequentially", limit: 0, wantLimit: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]int, 20)
+			var inFlight, maxInFlight atomic.Int64
+
+			MapConcurrent(items, tt.limit, func(int) (struct{}, error) {
+				current := inFlight.Add(1)
+				for {
+					seen := maxInFlight.Load()
+					if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				inFlight.Add(-1)
+				return struct{}{}, nil
+			})
+
+			if got := maxInFlight.Load(); got > tt.wantLimit {
+				t.Errorf("max concurrent calls = %d, want at most %d", got, tt.wantLimit)
+			}
+			if got := maxInFlight.Load(); got < tt.wantLimit {
+				t.Errorf("max concurrent calls = %d, want the limit %d to be reached", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestMapConcurrentEmpty(t *testing.T) {
+	results, errs := MapConcurrent([]int{}, 4, func(n int) (int, error) {
+		t.Fatal("fn called for an empty input")
+		return n, nil
+	})
+	if len(results) != 0 || len(errs) != 0 {
+		t.Errorf("got %d results and %d errors, want none", len(results), len(errs))
+	}
+}
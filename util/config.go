@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// PageSizes holds the default number of results tools return when the caller
+// does not ask for a specific amount.
+type PageSizes struct {
+	GmailSearch     int
+	CalendarEvents  int
+	CalendarSlots   int
+	ChatMessages    int
+	YouTubeVideos   int
+	YouTubeComments int
+	// GmailFilterApply caps how many existing messages a new filter's actions
+	// are applied to.
+	GmailFilterApply int
+}
+
+// DefaultPageSizes returns the page size defaults, each overridable through an
+// environment variable so deployments can tune output volume without code changes.
+var DefaultPageSizes = sync.OnceValue(func() PageSizes {
+	return PageSizes{
+		GmailSearch:      envInt("GMAIL_SEARCH_DEFAULT_RESULTS", 10),
+		CalendarEvents:   envInt("CALENDAR_LIST_DEFAULT_RESULTS", 10),
+		CalendarSlots:    envInt("CALENDAR_SLOTS_DEFAULT_RESULTS", 5),
+		ChatMessages:     envInt("GCHAT_MESSAGES_DEFAULT_PAGE_SIZE", 100),
+		YouTubeVideos:    envInt("YOUTUBE_VIDEOS_DEFAULT_RESULTS", 10),
+		YouTubeComments:  envInt("YOUTUBE_COMMENTS_DEFAULT_RESULTS", 20),
+		GmailFilterApply: envInt("GMAIL_FILTER_APPLY_MAX_MESSAGES", 500),
+	}
+})
+
+// MaxCalendarSpanDays returns the longest time range, in days, calendar tools
+// accept for listing events or querying free/busy, configurable through
+// CALENDAR_MAX_SPAN_DAYS.
+var MaxCalendarSpanDays = sync.OnceValue(func() int {
+	return envInt("CALENDAR_MAX_SPAN_DAYS", 90)
+})
+
+// envInt reads a positive integer from the environment, returning fallback
+// when the variable is unset or invalid.
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		fmt.Fprintf(os.Stderr, "Warning: invalid %s %q, using default %d\n", name, value, fallback)
+		return fallback
+	}
+	return n
+}
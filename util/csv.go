@@ -0,0 +1,94 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MapsToCSV flattens a list of records into CSV with a header row. Columns
+// listed in preferred come first in that order; any remaining keys follow in
+// alphabetical order. Slices of strings are joined with "; " and other
+// non-scalar values are JSON-encoded. Text that a spreadsheet would evaluate
+// as a formula is prefixed with a single quote (see escapeFormula).
+func MapsToCSV(rows []map[string]interface{}, preferred ...string) (string, error) {
+	seen := make(map[string]bool)
+	columns := make([]string, 0)
+	for _, column := range preferred {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	extra := make([]string, 0)
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				extra = append(extra, key)
+			}
+		}
+	}
+	sort.Strings(extra)
+	columns = append(columns, extra...)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(columns); err != nil {
+		return "", err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			value, err := csvValue(row[column])
+			if err != nil {
+				return "", fmt.Errorf("failed to encode column %s: %v", column, err)
+			}
+			record[i] = value
+		}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func csvValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return escapeFormula(v), nil
+	case []string:
+		return escapeFormula(strings.Join(v, "; ")), nil
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// escapeFormula prefixes text starting with a character spreadsheets treat as
+// the start of a formula with a single quote, so values such as email
+// subjects are shown as text instead of being evaluated when the CSV is opened.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
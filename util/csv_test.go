@@ -0,0 +1,32 @@
+package util
+
+import "testing"
+
+func TestMapsToCSVEscapesFormulas(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"subject": "=HYPERLINK(\"http://evil.example\",\"click\")", "count": -3},
+		{"subject": "+1 555 0100", "count": 2},
+		{"subject": "-draft", "count": 0},
+		{"subject": "@channel update", "count": 1},
+		{"subject": "\tindented", "count": 1},
+		{"subject": "Quarterly = good", "count": 1},
+		{"subject": []string{"=cmd", "safe"}, "count": 1},
+	}
+
+	got, err := MapsToCSV(rows, "subject", "count")
+	if err != nil {
+		t.Fatalf("MapsToCSV() = %v", err)
+	}
+
+	want := "subject,count\n" +
+		"\"'=HYPERLINK(\"\"http://evil.example\"\",\"\"click\"\")\",-3\n" +
+		"'+1 555 0100,2\n" +
+		"'-draft,0\n" +
+		"'@channel update,1\n" +
+		"'\tindented,1\n" +
+		"Quarterly = good,1\n" +
+		"'=cmd; safe,1\n"
+	if got != want {
+		t.Errorf("MapsToCSV() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// maxDownloadAttempts is how many times a download is attempted before giving up.
+const maxDownloadAttempts = 4
+
+// RangeFetcher opens a download starting at byte offset, sending a Range
+// header when offset is greater than zero.
+type RangeFetcher func(offset int64) (*http.Response, error)
+
+// IsTransientError reports whether a failed request is worth retrying: server
+// errors, rate limiting, and network failures that are not API errors. A
+// cancelled or timed-out context is never transient, since every retry would
+// fail the same way.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500 || apiErr.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// DownloadToFile streams a download into target and returns the number of
+// bytes written. Data goes to a temporary file next to target that is moved
+// into place only on success, so a partial file never appears at target. An
+// existing target is only replaced when overwrite is true. When the stream
+// fails partway, the remaining bytes are requested with a Range header; if the
+// server ignores it and sends the whole body, the download restarts from the
+// beginning. Failures writing the local file are not retried.
+func DownloadToFile(target string, overwrite bool, fetch RangeFetcher) (int64, error) {
+	if err := checkTarget(target, overwrite); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.part")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	var written int64
+	var lastErr error
+	for attempt := 0; attempt < maxDownloadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+
+		resp, err := fetch(written)
+		if err != nil {
+			if !IsTransientError(err) {
+				return 0, err
+			}
+			lastErr = err
+			continue
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusPartialContent:
+		case resp.StatusCode == http.StatusOK:
+			// The server sent the whole body, so start over
+			if written > 0 {
+				if err := tmp.Truncate(0); err != nil {
+					resp.Body.Close()
+					return 0, fmt.Errorf("failed to reset temporary file: %v", err)
+				}
+				if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+					resp.Body.Close()
+					return 0, fmt.Errorf("failed to reset temporary file: %v", err)
+				}
+				written = 0
+			}
+		case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+			resp.Body.Close()
+			lastErr = fmt.Errorf("server returned %s", resp.Status)
+			continue
+		default:
+			resp.Body.Close()
+			return 0, fmt.Errorf("download failed: server returned %s", resp.Status)
+		}
+
+		file := &fileWriter{file: tmp}
+		n, err := io.Copy(file, resp.Body)
+		resp.Body.Close()
+		written += n
+		if file.err != nil {
+			// A full disk or similar fails every retry the same way
+			return 0, fmt.Errorf("failed to write file: %v", file.err)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := tmp.Close(); err != nil {
+			return 0, fmt.Errorf("failed to write file: %v", err)
+		}
+		if err := moveIntoPlace(tmp.Name(), target, overwrite); err != nil {
+			return 0, err
+		}
+		return written, nil
+	}
+
+	return 0, fmt.Errorf("download failed after %d attempts: %v", maxDownloadAttempts, lastErr)
+}
+
+// WriteFileAtomic writes data to a temporary file next to target and moves it
+// into place, so a partial file never appears at target. An existing target is
+// only replaced when overwrite is true.
+func WriteFileAtomic(target string, data []byte, overwrite bool) error {
+	if err := checkTarget(target, overwrite); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.part")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return moveIntoPlace(tmp.Name(), target, overwrite)
+}
+
+// fileWriter records the error of a failed write, so a local write failure
+// can be told apart from a failed read of the download stream.
+type fileWriter struct {
+	file *os.File
+	err  error
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// checkTarget returns an error when target already exists and overwrite is
+// false, so a download cannot silently replace an unrelated file.
+func checkTarget(target string, overwrite bool) error {
+	if overwrite {
+		return nil
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("%s already exists; pass overwrite: true to replace it", target)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check %s: %v", target, err)
+	}
+	return nil
+}
+
+// moveIntoPlace moves the finished temporary file to target. Without
+// overwrite it hard-links instead of renaming, which fails if target appeared
+// in the meantime rather than replacing it.
+func moveIntoPlace(tmp, target string, overwrite bool) error {
+	if overwrite {
+		if err := os.Rename(tmp, target); err != nil {
+			return fmt.Errorf("failed to move file into place: %v", err)
+		}
+		return nil
+	}
+
+	err := os.Link(tmp, target)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists; pass overwrite: true to replace it", target)
+	}
+	// Some filesystems do not support hard links, so fall back to a checked rename
+	if err := checkTarget(target, false); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf("failed to move file into place: %v", err)
+	}
+	return nil
+}
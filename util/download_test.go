@@ -0,0 +1,117 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileAtomicOverwrite(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "attachment.txt")
+
+	if err := WriteFileAtomic(target, []byte("first"), false); err != nil {
+		t.Fatalf("WriteFileAtomic() to a new path: %v", err)
+	}
+	if err := WriteFileAtomic(target, []byte("second"), false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("WriteFileAtomic() over an existing file without overwrite = %v, want an already exists error", err)
+	}
+	assertFileContent(t, target, "first")
+
+	if err := WriteFileAtomic(target, []byte("third"), true); err != nil {
+		t.Fatalf("WriteFileAtomic() with overwrite: %v", err)
+	}
+	assertFileContent(t, target, "third")
+	assertNoTempFiles(t, filepath.Dir(target))
+}
+
+func TestDownloadToFileOverwrite(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "attachment.bin")
+	if err := os.WriteFile(target, []byte("keep me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fetched := false
+	_, err := DownloadToFile(target, false, func(offset int64) (*http.Response, error) {
+		fetched = true
+		return bodyResponse(http.StatusOK, "replaced"), nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("DownloadToFile() over an existing file without overwrite = %v, want an already exists error", err)
+	}
+	if fetched {
+		t.Error("DownloadToFile() fetched the download although the target exists")
+	}
+	assertFileContent(t, target, "keep me")
+
+	n, err := DownloadToFile(target, true, func(offset int64) (*http.Response, error) {
+		return bodyResponse(http.StatusOK, "replaced"), nil
+	})
+	if err != nil || n != int64(len("replaced")) {
+		t.Fatalf("DownloadToFile() with overwrite = %d, %v", n, err)
+	}
+	assertFileContent(t, target, "replaced")
+	assertNoTempFiles(t, filepath.Dir(target))
+}
+
+func TestDownloadToFileResumesWithRange(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "attachment.bin")
+
+	var offsets []int64
+	n, err := DownloadToFile(target, false, func(offset int64) (*http.Response, error) {
+		offsets = append(offsets, offset)
+		if offset == 0 {
+			// The stream breaks after the first half
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(io.MultiReader(strings.NewReader("hello "), errReader{})),
+			}, nil
+		}
+		return bodyResponse(http.StatusPartialContent, "world"), nil
+	})
+	if err != nil {
+		t.Fatalf("DownloadToFile() = %v", err)
+	}
+	if n != int64(len("hello world")) {
+		t.Errorf("DownloadToFile() wrote %d bytes, want %d", n, len("hello world"))
+	}
+	if len(offsets) != 2 || offsets[1] != int64(len("hello ")) {
+		t.Errorf("fetch offsets = %v, want [0 %d]", offsets, len("hello "))
+	}
+	assertFileContent(t, target, "hello world")
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+
+func bodyResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: io.NopCloser(bytes.NewBufferString(body))}
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if string(got) != want {
+		t.Errorf("%s contains %q, want %q", path, got, want)
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".part") {
+			t.Errorf("temporary file %s left behind", entry.Name())
+		}
+	}
+}
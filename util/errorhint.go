@@ -0,0 +1,75 @@
+package util
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ExplainErrors reports whether GOOGLE_MCP_EXPLAIN_ERRORS is "true", in which
+// case tool errors get a remediation hint appended (see ErrorHint).
+var ExplainErrors = sync.OnceValue(func() bool {
+	return os.Getenv("GOOGLE_MCP_EXPLAIN_ERRORS") == "true"
+})
+
+// apiErrorCodePattern matches the status code in a googleapi.Error message,
+// e.g. "googleapi: Error 404: Not Found, notFound".
+var apiErrorCodePattern = regexp.MustCompile(`googleapi: Error (\d{3})`)
+
+// notFoundHints explains a 404 per tool name prefix, naming the ID most likely
+// to be wrong. Prefixes are checked in order.
+var notFoundHints = []struct {
+	prefix string
+	hint   string
+}{
+	{"calendar_", "the event_id may be from a different calendar, or the calendar_id may be wrong; list events or calendars first"},
+	{"gmail_", "the message, thread, label or draft ID does not exist or was deleted; search or list first to get a current ID"},
+	{"gchat_", "the space or message name does not exist or the account is not a member; list spaces first and use the full name, e.g. spaces/AAAA..."},
+	{"youtube_", "the video, comment or playlist ID does not exist, is private, or belongs to another channel; list first to get a current ID"},
+	{"", "the requested resource does not exist; list first to get a current ID"},
+}
+
+// ErrorHint returns a short remediation hint for a tool error message, or ""
+// when the error is not recognized. Hints are chosen from the Google API
+// status code in the message and, for 404s, the tool name.
+func ErrorHint(toolName, message string) string {
+	switch {
+	case strings.Contains(message, "invalid_grant"):
+		return "the OAuth token was revoked or expired; re-run the token script (scripts/get-google-token)"
+	case strings.Contains(message, "quotaExceeded"), strings.Contains(message, "dailyLimitExceeded"):
+		return "the API quota is exhausted; wait for the quota to reset (daily quotas reset at midnight Pacific Time) or request more quota in the Google Cloud console"
+	case strings.Contains(message, "rateLimitExceeded"), strings.Contains(message, "userRateLimitExceeded"):
+		return "requests are being rate limited; wait a moment and retry with fewer items per call"
+	}
+
+	match := apiErrorCodePattern.FindStringSubmatch(message)
+	if match == nil {
+		return ""
+	}
+	code, _ := strconv.Atoi(match[1])
+	switch {
+	case code == 400:
+		return "a parameter is invalid; check IDs, date formats (RFC3339) and enum values against the tool description"
+	case code == 401:
+		return "the credentials were rejected; re-run the token script (scripts/get-google-token) or check the profile argument"
+	case code == 403:
+		return "the account lacks permission for this resource; check that it owns or has been shared the resource, and that the API is enabled for the Cloud project"
+	case code == 404:
+		for _, entry := range notFoundHints {
+			if strings.HasPrefix(toolName, entry.prefix) {
+				return entry.hint
+			}
+		}
+	case code == 409:
+		return "the resource already exists or was changed concurrently; fetch it again before retrying"
+	case code == 412:
+		return "the resource changed since it was read; fetch it again and retry the update"
+	case code == 429:
+		return "requests are being rate limited; wait a moment and retry with fewer items per call"
+	case code >= 500:
+		return "Google returned a server error, which is usually temporary; retry shortly"
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"log"
 	"runtime"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -33,3 +34,44 @@ func ErrorGuard(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
 		return result, nil
 	}
 }
+
+// ErrorGuardNamed is like ErrorGuard but prefixes recovered panics and errors
+// with the tool name and logs them, so failures can be traced to a tool.
+func ErrorGuardNamed(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(arguments map[string]interface{}) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				// Get stack trace
+				buf := make([]byte, 4096)
+				n := runtime.Stack(buf, false)
+				stackTrace := string(buf[:n])
+
+				log.Printf("ERROR [%s] panic: %v\n%s", name, r, stackTrace)
+				result = mcp.NewToolResultError(fmt.Sprintf("[%s] Panic: %v\nStack trace:\n%s", name, r, stackTrace))
+				err = nil
+			}
+		}()
+		result, err = handler(arguments)
+		if err != nil {
+			log.Printf("ERROR [%s] %v", name, err)
+			return mcp.NewToolResultError(fmt.Sprintf("[%s] Error: %v", name, err)), nil
+		}
+		if result != nil && result.IsError {
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					log.Printf("ERROR [%s] %s", name, text.Text)
+					text.Text = fmt.Sprintf("[%s] %s", name, text.Text)
+					if IsInsufficientScopeError(text.Text) {
+						text.Text += "\n" + MissingScopeHint(name)
+					} else if ExplainErrors() {
+						if hint := ErrorHint(name, text.Text); hint != "" {
+							text.Text += "\nHint: " + hint
+						}
+					}
+					result.Content[i] = text
+				}
+			}
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlHiddenBlocks = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<!--.*?-->`),
+		regexp.MustCompile(`(?is)<head\b.*?</head\s*>`),
+		regexp.MustCompile(`(?is)<script\b.*?</script\s*>`),
+		regexp.MustCompile(`(?is)<style\b.*?</style\s*>`),
+	}
+	htmlLink       = regexp.MustCompile(`(?is)<a\b[^>]*?\bhref\s*=\s*["']([^"']*)["'][^>]*>(.*?)</a\s*>`)
+	htmlLineBreak  = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlBlockEnd   = regexp.MustCompile(`(?i)</(p|div|tr|table|h[1-6]|ul|ol|blockquote|pre|section|article|header|footer)\s*>`)
+	htmlBlockStart = regexp.MustCompile(`(?i)<(p|div|table|h[1-6]|ul|ol|blockquote|pre)\b[^>]*>`)
+	htmlListItem   = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlTableCell  = regexp.MustCompile(`(?i)</t[dh]\s*>`)
+	htmlTag        = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlSpaces     = regexp.MustCompile(`[ \t\f\v]+`)
+	htmlBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText converts an HTML email body into readable plain text. Tags are
+// stripped, entities decoded, block elements and <br> become line breaks, list
+// items get a "- " prefix, and links keep their target as "text (url)".
+func HTMLToText(body string) string {
+	for _, block := range htmlHiddenBlocks {
+		body = block.ReplaceAllString(body, "")
+	}
+
+	// Source formatting whitespace is not significant in HTML
+	body = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(body)
+
+	body = htmlLink.ReplaceAllStringFunc(body, func(link string) string {
+		match := htmlLink.FindStringSubmatch(link)
+		href := strings.TrimSpace(html.UnescapeString(match[1]))
+		text := strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(match[2], "")))
+		switch {
+		case href == "" || strings.HasPrefix(href, "#"):
+			return text
+		case text == "" || text == href || "mailto:"+text == href:
+			return href
+		default:
+			return text + " (" + href + ")"
+		}
+	})
+
+	body = htmlLineBreak.ReplaceAllString(body, "\n")
+	body = htmlListItem.ReplaceAllString(body, "\n- ")
+	body = htmlBlockStart.ReplaceAllString(body, "\n")
+	body = htmlBlockEnd.ReplaceAllString(body, "\n")
+	body = htmlTableCell.ReplaceAllString(body, " ")
+	body = htmlTag.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+	body = strings.ReplaceAll(body, "\u00a0", " ")
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(htmlSpaces.ReplaceAllString(line, " "))
+	}
+	body = strings.Join(lines, "\n")
+	body = htmlBlankLines.ReplaceAllString(body, "\n\n")
+
+	return strings.TrimSpace(body)
+}
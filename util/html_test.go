@@ -0,0 +1,135 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "entities",
+			html: `<p>Fish &amp; Chips &lt;today&gt; &quot;fresh&quot; &#39;hot&#39; &eacute;t&eacute;&nbsp;menu &#8364;5</p>`,
+			want: `Fish & Chips <today> "fresh" 'hot' été menu €5`,
+		},
+		{
+			name: "br line breaks",
+			html: `Line one<br>Line two<br/>Line three<BR />Line four`,
+			want: "Line one\nLine two\nLine three\nLine four",
+		},
+		{
+			name: "paragraphs",
+			html: "<p>First paragraph.</p>\n<p>Second\n   paragraph   with    spaces.</p>",
+			want: "First paragraph.\n\nSecond paragraph with spaces.",
+		},
+		{
+			name: "links",
+			html: `<a href="https://example.com/docs">Read the docs</a>, <a href="https://example.com">https://example.com</a>, <a href="mailto:team@example.com">team@example.com</a> and <a href="#top">back to top</a>`,
+			want: "Read the docs (https://example.com/docs), https://example.com, mailto:team@example.com and back to top",
+		},
+		{
+			name: "link with escaped href and nested tags",
+			html: `<a href="https://example.com/?a=1&amp;b=2"><b>Open</b></a>`,
+			want: "Open (https://example.com/?a=1&b=2)",
+		},
+		{
+			name: "style, script, head and comments removed",
+			html: `<html><head><title>Hidden title</title></head><body><style>p { color: red; }</style><script type="text/javascript">alert("x");</script><!-- tracking comment --><p>Visible</p></body></html>`,
+			want: "Visible",
+		},
+		{
+			name: "lists",
+			html: `<ul><li>Apples</li><li>Pears</li></ul>`,
+			want: "- Apples\n- Pears",
+		},
+		{
+			name: "table cells",
+			html: `<table><tr><td>Name</td><td>Qty</td></tr><tr><td>Widget</td><td>3</td></tr></table>`,
+			want: "Name Qty\nWidget 3",
+		},
+		{
+			name: "plain text unchanged",
+			html: "Just text",
+			want: "Just text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.html); got != tt.want {
+				t.Errorf("HTMLToText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLToTextNewsletter(t *testing.T) {
+	newsletter := `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <style type="text/css">
+    .button { background: #1a73e8; color: #fff; }
+    @media (max-width: 600px) { .col { width: 100% !important; } }
+  </style>
+</head>
+<body>
+  <!--[if mso]><table><tr><td><![endif]-->
+  <table role="presentation" width="100%" cellpadding="0">
+    <tr>
+      <td class="header"><h1>Weekly&nbsp;Digest</h1></td>
+    </tr>
+    <tr>
+      <td>
+        <p>Hi Alex,</p>
+        <p>Here&rsquo;s what happened this week:</p>
+        <ul>
+          <li><a href="https://news.example.com/a?utm_source=email&amp;utm_medium=digest">Release 2.0 is out</a></li>
+          <li>Q3 roadmap &mdash; now public</li>
+        </ul>
+        <p><a class="button" href="https://news.example.com/all">Read more</a></p>
+      </td>
+    </tr>
+    <tr>
+      <td class="footer">
+        <p>You are receiving this because you subscribed.<br>
+        <a href="https://news.example.com/unsubscribe">Unsubscribe</a></p>
+      </td>
+    </tr>
+  </table>
+  <script>trackOpen();</script>
+  <img src="https://news.example.com/pixel.gif" width="1" height="1" alt="">
+</body>
+</html>`
+
+	got := HTMLToText(newsletter)
+
+	want := strings.Join([]string{
+		"Weekly Digest",
+		"",
+		"Hi Alex,",
+		"",
+		"Here’s what happened this week:",
+		"",
+		"- Release 2.0 is out (https://news.example.com/a?utm_source=email&utm_medium=digest)",
+		"- Q3 roadmap — now public",
+		"",
+		"Read more (https://news.example.com/all)",
+		"",
+		"You are receiving this because you subscribed.",
+		"Unsubscribe (https://news.example.com/unsubscribe)",
+	}, "\n")
+	if got != want {
+		t.Errorf("HTMLToText() =\n%s\n\nwant:\n%s", got, want)
+	}
+
+	for _, hidden := range []string{"background", "@media", "trackOpen", "mso", "pixel.gif"} {
+		if strings.Contains(got, hidden) {
+			t.Errorf("output contains %q, which should have been removed", hidden)
+		}
+	}
+}
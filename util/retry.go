@@ -0,0 +1,84 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// maxRetryAttempts is how many times Retry attempts a request before giving up.
+const maxRetryAttempts = 3
+
+// ErrBatchSkipped marks batch items that were not attempted because an
+// earlier item failed with an error every remaining item would hit too.
+var ErrBatchSkipped = errors.New("skipped after an earlier item failed with an unrecoverable error")
+
+// Retry calls fn until it succeeds, fails with an error that is not transient,
+// or maxRetryAttempts attempts have been made, waiting a little longer before
+// each retry.
+func Retry[T any](fn func() (T, error)) (T, error) {
+	var result T
+	var err error
+	for attempt := 0; attempt < maxRetryAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+		result, err = fn()
+		if err == nil || !IsTransientError(err) {
+			break
+		}
+	}
+	return result, err
+}
+
+// IsBatchFatalError reports whether an error means every remaining item of a
+// batch would fail the same way: the credentials were rejected or lack a
+// required scope.
+func IsBatchFatalError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized {
+		return true
+	}
+	return IsInsufficientScopeError(err.Error())
+}
+
+// RunBatch calls fn for every item with at most limit calls in flight,
+// retrying transient failures with Retry. A failed item does not stop the
+// batch, except after a fatal error (see IsBatchFatalError), when items not yet
+// started fail with ErrBatchSkipped. Results and errors are in input order.
+func RunBatch[In, Out any](items []In, limit int, fn func(In) (Out, error)) ([]Out, []error) {
+	var fatal atomic.Bool
+	return MapConcurrent(items, limit, func(item In) (Out, error) {
+		if fatal.Load() {
+			var zero Out
+			return zero, ErrBatchSkipped
+		}
+		result, err := Retry(func() (Out, error) { return fn(item) })
+		if IsBatchFatalError(err) {
+			fatal.Store(true)
+		}
+		return result, err
+	})
+}
+
+// BatchCounts counts the succeeded, failed and skipped items of a batch from
+// the errors RunBatch returned.
+func BatchCounts(errs []error) (succeeded, failed, skipped int) {
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrBatchSkipped):
+			skipped++
+		default:
+			failed++
+		}
+	}
+	return succeeded, failed, skipped
+}
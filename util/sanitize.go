@@ -0,0 +1,99 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultMaxFieldLength bounds a single text field when MAX_FIELD_LENGTH is unset.
+const defaultMaxFieldLength = 50000
+
+// MaxFieldLength returns the maximum number of characters kept per text field,
+// configured via MAX_FIELD_LENGTH. Zero or a negative value disables truncation.
+var MaxFieldLength = sync.OnceValue(func() int {
+	value := os.Getenv("MAX_FIELD_LENGTH")
+	if value == "" {
+		return defaultMaxFieldLength
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid MAX_FIELD_LENGTH %q: %v\n", value, err)
+		return defaultMaxFieldLength
+	}
+	return n
+})
+
+// SanitizeString prepares user-provided text for structured output: it fixes
+// invalid UTF-8, normalizes line endings, drops control characters other than
+// newlines and tabs, and truncates the result to MaxFieldLength characters.
+func SanitizeString(s string) string {
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "\uFFFD")
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r == '\r':
+			return '\n'
+		case unicode.IsControl(r), r == '\uFEFF':
+			return -1
+		}
+		return r
+	}, s)
+
+	if limit := MaxFieldLength(); limit > 0 && utf8.RuneCountInString(s) > limit {
+		runes := []rune(s)
+		s = string(runes[:limit]) + fmt.Sprintf("… [truncated %d characters]", len(runes)-limit)
+	}
+
+	return s
+}
+
+// SanitizeValue applies SanitizeString to every string inside the maps and
+// slices that tool handlers build for their results, in place where possible.
+func SanitizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return SanitizeString(v)
+	case []string:
+		for i := range v {
+			v[i] = SanitizeString(v[i])
+		}
+		return v
+	case map[string]string:
+		for key, item := range v {
+			v[key] = SanitizeString(item)
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = SanitizeValue(item)
+		}
+		return v
+	case []map[string]interface{}:
+		for i := range v {
+			SanitizeValue(v[i])
+		}
+		return v
+	case []map[string]string:
+		for i := range v {
+			SanitizeValue(v[i])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = SanitizeValue(v[i])
+		}
+		return v
+	default:
+		return value
+	}
+}
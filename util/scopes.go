@@ -0,0 +1,62 @@
+package util
+
+import "strings"
+
+// insufficientScopeMarkers are substrings of Google API errors returned when
+// the access token lacks a scope the request needs.
+var insufficientScopeMarkers = []string{
+	"insufficient authentication scopes",
+	"ACCESS_TOKEN_SCOPE_INSUFFICIENT",
+	"insufficientPermissions",
+}
+
+// toolScopes maps tool names, or tool name prefixes ending in "_", to the
+// OAuth scopes they need. Exact names are checked before prefixes.
+var toolScopes = map[string][]string{
+	"gmail_settings":        {"https://www.googleapis.com/auth/gmail.settings.basic", "https://www.googleapis.com/auth/gmail.settings.sharing"},
+	"gmail_forwarding":      {"https://www.googleapis.com/auth/gmail.settings.basic", "https://www.googleapis.com/auth/gmail.settings.sharing"},
+	"gmail_vacation":        {"https://www.googleapis.com/auth/gmail.settings.basic"},
+	"gmail_filter":          {"https://www.googleapis.com/auth/gmail.settings.basic"},
+	"gmail_":                {"https://www.googleapis.com/auth/gmail.modify"},
+	"calendar_event":        {"https://www.googleapis.com/auth/calendar", "https://www.googleapis.com/auth/drive.file", "https://www.googleapis.com/auth/drive.metadata.readonly"},
+	"calendar_":             {"https://www.googleapis.com/auth/calendar"},
+	"gchat_directory_user":  {"https://www.googleapis.com/auth/admin.directory.user.readonly"},
+	"gchat_":                {"https://www.googleapis.com/auth/chat.messages", "https://www.googleapis.com/auth/chat.spaces", "https://www.googleapis.com/auth/chat.memberships"},
+	"youtube_channel_audit": {"https://www.googleapis.com/auth/youtubepartner-channel-audit"},
+	"youtube_":              {"https://www.googleapis.com/auth/youtube.force-ssl"},
+}
+
+// IsInsufficientScopeError reports whether an error message is Google's
+// response to a token missing a required scope.
+func IsInsufficientScopeError(message string) bool {
+	for _, marker := range insufficientScopeMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredScopes returns the OAuth scopes the named tool needs, or nil when
+// the tool is unknown.
+func RequiredScopes(toolName string) []string {
+	if scopes, ok := toolScopes[toolName]; ok {
+		return scopes
+	}
+	for prefix, scopes := range toolScopes {
+		if strings.HasSuffix(prefix, "_") && strings.HasPrefix(toolName, prefix) {
+			return scopes
+		}
+	}
+	return nil
+}
+
+// MissingScopeHint explains which scopes a tool needs after it failed with an
+// insufficient-scope error, and how to grant them.
+func MissingScopeHint(toolName string) string {
+	hint := "the access token is missing a required scope"
+	if scopes := RequiredScopes(toolName); len(scopes) > 0 {
+		hint = "this requires " + strings.Join(scopes, ", ")
+	}
+	return hint + " — re-run the token script (scripts/get-google-token) so the token is granted every scope the server requests"
+}
@@ -0,0 +1,147 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// offsetTimeLayouts are near-RFC3339 variants accepted with an explicit UTC
+// offset: a space instead of "T", or no seconds.
+var offsetTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04Z07:00",
+}
+
+// localTimeLayouts are accepted when an input has no UTC offset; such values
+// are interpreted in the default timezone.
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// DefaultLocation returns the timezone configured via DEFAULT_TIMEZONE (an IANA
+// name such as "Asia/Ho_Chi_Minh"), falling back to the local timezone.
+var DefaultLocation = sync.OnceValue(func() *time.Location {
+	name := os.Getenv("DEFAULT_TIMEZONE")
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid DEFAULT_TIMEZONE %q: %v\n", name, err)
+		return time.Local
+	}
+	return loc
+})
+
+// ParseTime parses an RFC3339 timestamp, leniently accepting common variants:
+// a space instead of "T" and a missing seconds field. Values without a UTC
+// offset (e.g. 2024-01-02 15:04) are interpreted in DefaultLocation. Callers
+// should format the result with time.RFC3339 to report the normalized value.
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range offsetTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.In(DefaultLocation()), nil
+		}
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, DefaultLocation()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a valid RFC3339 time; use a form like %s", value, time.Date(2024, 6, 1, 15, 0, 0, 0, DefaultLocation()).Format(time.RFC3339))
+}
+
+// ParseTimeRange parses and validates a start/end pair, ensuring start is not
+// after end. All problems are reported together in a single error.
+func ParseTimeRange(start, end string) (time.Time, time.Time, error) {
+	var errs []error
+
+	startTime, err := ParseTime(start)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid start time: %v", err))
+	}
+	endTime, err := ParseTime(end)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid end time: %v", err))
+	}
+	if len(errs) == 0 && startTime.After(endTime) {
+		errs = append(errs, fmt.Errorf("start time %s is after end time %s", start, end))
+	}
+
+	if len(errs) > 0 {
+		return time.Time{}, time.Time{}, errors.Join(errs...)
+	}
+	return startTime, endTime, nil
+}
+
+var relativeOffsetPattern = regexp.MustCompile(`^(?:in\s+|\+)?(\d+)\s*(m|min|mins|minutes?|h|hrs?|hours?|d|days?|w|weeks?)(?:\s+from\s+now)?$`)
+
+// ParseRelativeTime parses a human-friendly time relative to now in addition
+// to everything ParseTime accepts: a bare date (2024-01-02), "now", "today",
+// "tomorrow", "yesterday", a weekday name ("friday", "next friday") meaning its
+// next occurrence, and offsets such as "in 3 days", "+2w" or "4 hours from now".
+// Dates and weekdays resolve to midnight in DefaultLocation.
+func ParseRelativeTime(value string, now time.Time) (time.Time, error) {
+	if t, err := ParseTime(value); err == nil {
+		return t, nil
+	}
+
+	loc := DefaultLocation()
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	switch normalized {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	weekdayName := strings.TrimPrefix(normalized, "next ")
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if weekdayName == strings.ToLower(day.String()) {
+			days := (int(day) - int(now.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return midnight.AddDate(0, 0, days), nil
+		}
+	}
+
+	if match := relativeOffsetPattern.FindStringSubmatch(normalized); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2][0] {
+		case 'm':
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case 'h':
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case 'd':
+			return now.AddDate(0, 0, n), nil
+		case 'w':
+			return now.AddDate(0, 0, 7*n), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a recognized time; use RFC3339, a date (2006-01-02), today, tomorrow, a weekday, or an offset like \"in 3 days\"", value)
+}
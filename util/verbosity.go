@@ -0,0 +1,85 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Verbosity controls how many fields list tools include per item.
+type Verbosity int
+
+const (
+	// VerbosityTerse keeps only the fields that identify each item.
+	VerbosityTerse Verbosity = iota
+	// VerbosityNormal keeps the fields list tools return by default.
+	VerbosityNormal
+	// VerbosityVerbose adds every extra field the tool maps.
+	VerbosityVerbose
+)
+
+// OutputVerbosity returns the verbosity configured via GOOGLE_MCP_VERBOSITY
+// (terse, normal or verbose), defaulting to normal.
+var OutputVerbosity = sync.OnceValue(func() Verbosity {
+	value := os.Getenv("GOOGLE_MCP_VERBOSITY")
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "normal":
+		return VerbosityNormal
+	case "terse":
+		return VerbosityTerse
+	case "verbose":
+		return VerbosityVerbose
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: invalid GOOGLE_MCP_VERBOSITY %q, using normal\n", value)
+		return VerbosityNormal
+	}
+})
+
+// FieldSet describes which fields of a list item each verbosity includes.
+// Fields in neither list are included at normal and verbose.
+type FieldSet struct {
+	// Terse lists the identifying fields, the only ones kept at terse.
+	Terse []string
+	// Verbose lists extra fields that are only kept at verbose.
+	Verbose []string
+}
+
+// Select removes the fields the configured verbosity excludes from each item,
+// in place, and returns items.
+func (f FieldSet) Select(items []map[string]interface{}) []map[string]interface{} {
+	verbosity := OutputVerbosity()
+	for _, item := range items {
+		for key := range item {
+			if !f.includes(verbosity, key) {
+				delete(item, key)
+			}
+		}
+	}
+	return items
+}
+
+// Columns filters a CSV column list down to the fields the configured
+// verbosity includes, keeping their order.
+func (f FieldSet) Columns(columns ...string) []string {
+	verbosity := OutputVerbosity()
+	selected := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if f.includes(verbosity, column) {
+			selected = append(selected, column)
+		}
+	}
+	return selected
+}
+
+func (f FieldSet) includes(verbosity Verbosity, field string) bool {
+	switch verbosity {
+	case VerbosityTerse:
+		return slices.Contains(f.Terse, field)
+	case VerbosityNormal:
+		return !slices.Contains(f.Verbose, field)
+	default:
+		return true
+	}
+}